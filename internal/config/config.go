@@ -10,22 +10,29 @@ import (
 
 // Config holds all server configuration
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Logger   Logger
+	Server      ServerConfig
+	Postgres    PostgresConfig
+	Logger      Logger
+	Calculation CalculationConfig
 }
 
 // ServerConfig has all servec config properties
 type ServerConfig struct {
-	AppVersion        string
-	Port              string
-	Mode              string
-	ReadTimeout       time.Duration
-	WriteTimeout      time.Duration
-	WeatherApiUrl     string
-	WeatherSecret     string
-	CtxDefaultTimeout time.Duration
-	Debug             bool
+	AppVersion    string
+	Port          string
+	Mode          string
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	WeatherApiUrl string
+	WeatherSecret string
+	// HourlyWeatherApiUrl is a weather api by position and time, the day only api is used when empty
+	HourlyWeatherApiUrl string
+	// WeatherTimeoutInSeconds bounds a call to the hourly weather api, 10 seconds by default
+	WeatherTimeoutInSeconds int
+	// HourlyWeatherCacheSize is the number of hourly weather samples kept, the least recently used are evicted, 100000 by default
+	HourlyWeatherCacheSize int
+	CtxDefaultTimeout      time.Duration
+	Debug                  bool
 }
 
 // Logger config
@@ -37,6 +44,65 @@ type Logger struct {
 	Level             string
 }
 
+// CalculationConfig holds defaults used by the consumption calculation
+type CalculationConfig struct {
+	DistanceModel   string
+	DefaultFuelType string
+	// MaxSpeedInKnot is the speed above which route datapoints are dropped as GPS jumps
+	MaxSpeedInKnot float64
+	// DesignSpeedMargin is the multiple of the design speed of a vessel used as its max speed instead of MaxSpeedInKnot
+	DesignSpeedMargin float64
+	// IdleSpeedInKnot and ManoeuvringSpeedInKnot are the avg speeds below which legs are not sailing
+	IdleSpeedInKnot        float64
+	ManoeuvringSpeedInKnot float64
+	// AuxiliaryConsumption are the default daily consumptions of non sailing states
+	AuxiliaryConsumption AuxiliaryConsumption
+	// WeatherSampleInHours is the interval legs are sampled at along their track for weather
+	WeatherSampleInHours float64
+	// DirectionalFactors scale the weather part of the consumption by relative wind sector
+	DirectionalFactors DirectionalFactors
+	// FallbackBeaufort is used for legs without any weather from the weather client
+	FallbackBeaufort float64
+	// MaxGapInHours is the leg duration above which a route is warned about
+	MaxGapInHours float64
+	// DraughtToleranceInMeters is the distance to the closest fuel table draught above which a route is warned about
+	DraughtToleranceInMeters float64
+	// BatchConcurrency is the number of batch entries calculated at the same time
+	BatchConcurrency int
+	// OutlierThreshold is the robust z-score of the residual to the fitted curve above which a fuel table row is an outlier
+	OutlierThreshold float64
+	// EmissionFactors are tonnes of CO2 per tonne of fuel keyed by fuel type
+	EmissionFactors map[string]float64
+	// GHGFactors are the FuelEU well to wake factors keyed by fuel type
+	GHGFactors map[string]GHGFactors
+}
+
+// AuxiliaryConsumption holds daily consumption in metric tons of non sailing states
+type AuxiliaryConsumption struct {
+	Manoeuvring float64
+	Anchored    float64
+	Berthed     float64
+}
+
+// DirectionalFactors holds the factors of the weather part of the consumption for every wind sector
+type DirectionalFactors struct {
+	Head      float64
+	Beam      float64
+	Following float64
+}
+
+// GHGFactors holds the well to wake emission factors of a fuel
+type GHGFactors struct {
+	LCV    float64
+	WtTCO2 float64
+	WtTCH4 float64
+	WtTN2O float64
+	TtWCO2 float64
+	TtWCH4 float64
+	TtWN2O float64
+	Slip   float64
+}
+
 // PostgresConfig holds all the postgres configuration vars
 type PostgresConfig struct {
 	PostgresqlHost     string
@@ -0,0 +1,48 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type CIIHandlers interface {
+	GetCIIRating() echo.HandlerFunc
+}
+
+type ciiHandlers struct {
+	cfg    *config.Config
+	cs     service.CIIService
+	logger logger.Logger
+}
+
+// NewCIIHandlers CII handlers constructor
+func NewCIIHandlers(cfg *config.Config, cs service.CIIService, logger logger.Logger) CIIHandlers {
+	return &ciiHandlers{cfg: cfg, cs: cs, logger: logger}
+}
+
+func (h ciiHandlers) GetCIIRating() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		req := &GetCIIRequest{}
+		if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		rating, err := h.cs.GetCIIRating(ctx, imo, req.Year, req.Draught, req.Routes, req.Options())
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewCIIView(rating))
+	}
+}
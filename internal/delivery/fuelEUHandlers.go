@@ -0,0 +1,48 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type FuelEUHandlers interface {
+	GetFuelEUBalance() echo.HandlerFunc
+}
+
+type fuelEUHandlers struct {
+	cfg    *config.Config
+	fs     service.FuelEUService
+	logger logger.Logger
+}
+
+// NewFuelEUHandlers FuelEU handlers constructor
+func NewFuelEUHandlers(cfg *config.Config, fs service.FuelEUService, logger logger.Logger) FuelEUHandlers {
+	return &fuelEUHandlers{cfg: cfg, fs: fs, logger: logger}
+}
+
+func (h fuelEUHandlers) GetFuelEUBalance() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		req := &GetFuelEUBalanceRequest{}
+		if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		balance, err := h.fs.GetFuelEUBalance(ctx, imo, req.Year, req.Draught, req.Routes, req.FuelMix, req.Options())
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewFuelEUView(balance))
+	}
+}
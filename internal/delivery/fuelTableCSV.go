@@ -0,0 +1,111 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// MIMETextCSV is the content type of fuel tables uploaded and downloaded as csv
+const MIMETextCSV = "text/csv"
+
+// fuelTableColumns are the csv columns of a fuel table, imo and wind_sector are optional on upload
+var fuelTableColumns = []string{"draught", "speed", "beaufort", "consumption", "imo", "wind_sector"}
+
+// readFuelTableCSV reads a fuel table csv with a header row, in the format of the files in /csv
+func readFuelTableCSV(r io.Reader) (*FuelTableRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range fuelTableColumns[:4] {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	req := &FuelTableRequest{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		row := &FuelTableRowRequest{}
+		targets := []*float64{&row.Draught, &row.Speed, &row.Beaufort, &row.Consumption}
+		for i, name := range fuelTableColumns[:4] {
+			if *targets[i], err = strconv.ParseFloat(strings.TrimSpace(record[columns[name]]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: %s is not a number", line, name)
+			}
+		}
+		if i, exists := columns["imo"]; exists {
+			imo, err := strconv.Atoi(strings.TrimSpace(record[i]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: imo is not a number", line)
+			}
+			row.Imo = &imo
+		}
+		if i, exists := columns["wind_sector"]; exists {
+			row.WindSector = strings.ToLower(strings.TrimSpace(record[i]))
+		}
+		req.Rows = append(req.Rows, row)
+	}
+	return req, nil
+}
+
+// writeFuelTableCSV writes the fuel table as csv, wind_sector is only written when a row has one
+func writeFuelTableCSV(imo int, fuelMaps []*domain.FuelMap) ([]byte, error) {
+	withSector := false
+	for _, fm := range fuelMaps {
+		if fm.WindSector != nil {
+			withSector = true
+			break
+		}
+	}
+	columns := fuelTableColumns[:5]
+	if withSector {
+		columns = fuelTableColumns
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	for _, fm := range fuelMaps {
+		record := []string{
+			strconv.FormatFloat(fm.Draught, 'f', -1, 64),
+			strconv.FormatFloat(fm.Speed, 'f', -1, 64),
+			strconv.FormatFloat(fm.Weather, 'f', -1, 64),
+			strconv.FormatFloat(fm.Consumtion, 'f', -1, 64),
+			strconv.Itoa(imo),
+		}
+		if withSector {
+			sector := ""
+			if fm.WindSector != nil {
+				sector = string(*fm.WindSector)
+			}
+			record = append(record, sector)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -0,0 +1,172 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type FuelTableHandlers interface {
+	ListFuelTables() echo.HandlerFunc
+	GetFuelTable() echo.HandlerFunc
+	GetFuelTableVersions() echo.HandlerFunc
+	GetFuelTableQuality() echo.HandlerFunc
+	UploadFuelTable() echo.HandlerFunc
+	DeleteFuelTable() echo.HandlerFunc
+}
+
+type fuelTableHandlers struct {
+	cfg    *config.Config
+	fs     service.FuelTableService
+	logger logger.Logger
+}
+
+// NewFuelTableHandlers fuel table handlers constructor
+func NewFuelTableHandlers(cfg *config.Config, fs service.FuelTableService, logger logger.Logger) FuelTableHandlers {
+	return &fuelTableHandlers{cfg: cfg, fs: fs, logger: logger}
+}
+
+func (h fuelTableHandlers) ListFuelTables() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		summaries, err := h.fs.ListFuelTables(ctx)
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewFuelTableSummariesView(summaries))
+	}
+}
+
+func (h fuelTableHandlers) GetFuelTable() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+		query := &FuelTableQuery{}
+		if err := ReadQuery(c, query); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		rows, err := h.fs.GetFuelTable(ctx, imo, atOrNow(query.At))
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+
+		if query.Format != "csv" {
+			return c.JSON(http.StatusOK, NewFuelTableView(imo, rows))
+		}
+		body, err := writeFuelTableCSV(imo, rows)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, errors.E(errors.Op("delivery.GetFuelTable"), errors.KindInternal, err))
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"fuel_%d.csv\"", imo))
+		return c.Blob(http.StatusOK, MIMETextCSV, body)
+	}
+}
+
+func (h fuelTableHandlers) GetFuelTableVersions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		versions, err := h.fs.GetFuelTableVersions(ctx, imo)
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewFuelTableSummariesView(versions))
+	}
+}
+
+func (h fuelTableHandlers) GetFuelTableQuality() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+		query := &FuelTableQualityQuery{}
+		if err := ReadQuery(c, query); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		quality, err := h.fs.GetFuelTableQuality(ctx, imo, atOrNow(query.At))
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewFuelTableQualityView(quality))
+	}
+}
+
+// UploadFuelTable saves the uploaded fuel table as a version of the vessel fuel table,
+// sent as csv with a text/csv content type or as json otherwise
+func (h fuelTableHandlers) UploadFuelTable() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+		operation := errors.Op("delivery.UploadFuelTable")
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+		query := &FuelTableUploadQuery{}
+		if err := ReadQuery(c, query); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		req := &FuelTableRequest{}
+		if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), MIMETextCSV) {
+			defer c.Request().Body.Close()
+			if req, err = readFuelTableCSV(c.Request().Body); err != nil {
+				return ErrResponseWithLog(c, h.logger, errors.E(operation, errors.KindBadInput, err))
+			}
+			if err = ValidateStruct(ctx, req); err != nil {
+				return ErrResponseWithLog(c, h.logger, errors.E(operation, errors.KindBadInput, err))
+			}
+		} else if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		quality, err := h.fs.ReplaceFuelTable(ctx, imo, query.VersionStart(), req.FuelMaps(imo))
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewFuelTableQualityView(quality))
+	}
+}
+
+func (h fuelTableHandlers) DeleteFuelTable() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		if err := h.fs.DeleteFuelTable(ctx, imo); err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
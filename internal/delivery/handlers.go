@@ -11,6 +11,8 @@ import (
 
 type VesselsHandlers interface {
 	GetRoutesConsumtion() echo.HandlerFunc
+	GetBatchConsumption() echo.HandlerFunc
+	GetConsumptionCurves() echo.HandlerFunc
 }
 
 type vesselsHandlers struct {
@@ -29,16 +31,70 @@ func (h vesselsHandlers) GetRoutesConsumtion() echo.HandlerFunc {
 		ctx := GetRequestCtx(c)
 
 		req := &GetRoutesConsumptionRequest{}
+		query := &ConsumptionQuery{}
 
+		if err := ReadQuery(c, query); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+		if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		rowRes, err := h.vs.GetRoutesConsumtion(ctx, req.Imo, req.Draught, req.Routes, req.Options())
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewResponseView(rowRes, query.Detail == DetailLegs))
+	}
+}
+
+func (h vesselsHandlers) GetBatchConsumption() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		req := &BatchConsumptionRequest{}
+		query := &ConsumptionQuery{}
+
+		if err := ReadQuery(c, query); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
 		if err := SanitizeRequest(c, req); err != nil {
 			return ErrResponseWithLog(c, h.logger, err)
 		}
 
-		rowRes, err := h.vs.GetRoutesConsumtion(ctx, req.Imo, req.Draught, req.Routes)
+		results, err := h.vs.GetBatchConsumption(ctx, req.BatchEntries())
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		for id, result := range results {
+			if result.Err != nil {
+				h.logger.Warnf("batch entry %s failed: %v", id, result.Err)
+			}
+		}
+		return c.JSON(http.StatusOK, NewBatchView(results, query.Detail == DetailLegs))
+	}
+}
+
+func (h vesselsHandlers) GetConsumptionCurves() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+		query := &CurvesQuery{}
+		if err := ReadQuery(c, query); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		curves, err := h.vs.GetConsumptionCurves(ctx, imo, atOrNow(query.At))
 		if err != nil {
 			LogResponseError(c, h.logger, err)
 			return c.JSON(ErrorResponse(err))
 		}
-		return c.JSON(http.StatusOK, NewResponseView(rowRes))
+		return c.JSON(http.StatusOK, NewCurvesView(curves))
 	}
 }
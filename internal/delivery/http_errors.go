@@ -129,6 +129,8 @@ func ParseErrors(err error) RestErr {
 		return NewRestError(http.StatusInternalServerError, ErrInternalServerError.Error(), errors.Unwrap(err).Error())
 	case custtomerrors.IsKind(custtomerrors.KindBadInput, err):
 		return NewRestError(http.StatusBadRequest, ErrBadRequest.Error(), errors.Unwrap(err).Error())
+	case custtomerrors.IsKind(custtomerrors.KindNotFound, err):
+		return NewRestError(http.StatusNotFound, ErrNotFound.Error(), errors.Unwrap(err).Error())
 	case custtomerrors.IsKind(custtomerrors.KindExternalRPC, err):
 		return NewRestError(http.StatusServiceUnavailable, ErrServiceUnavailable.Error(), errors.Unwrap(err).Error())
 	case custtomerrors.IsKind(custtomerrors.KindNotAuthorized, err):
@@ -0,0 +1,99 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type RegistryHandlers interface {
+	GetVessel() echo.HandlerFunc
+	ListVessels() echo.HandlerFunc
+	SaveVessel() echo.HandlerFunc
+	DeleteVessel() echo.HandlerFunc
+}
+
+type registryHandlers struct {
+	cfg    *config.Config
+	rs     service.RegistryService
+	logger logger.Logger
+}
+
+// NewRegistryHandlers registry handlers constructor
+func NewRegistryHandlers(cfg *config.Config, rs service.RegistryService, logger logger.Logger) RegistryHandlers {
+	return &registryHandlers{cfg: cfg, rs: rs, logger: logger}
+}
+
+func (h registryHandlers) GetVessel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		vessel, err := h.rs.GetVessel(ctx, imo)
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewVesselView(vessel))
+	}
+}
+
+func (h registryHandlers) ListVessels() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		vessels, err := h.rs.ListVessels(ctx)
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewVesselsView(vessels))
+	}
+}
+
+func (h registryHandlers) SaveVessel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		req := &SaveVesselRequest{}
+		if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		vessel, err := h.rs.SaveVessel(ctx, req.Vessel(imo))
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewVesselView(vessel))
+	}
+}
+
+func (h registryHandlers) DeleteVessel() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		if err := h.rs.DeleteVessel(ctx, imo); err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -1,11 +1,261 @@
 package delivery
 
 import (
+	"time"
+
 	"github.com/kkr2/vessels/internal/domain"
 )
 
 type GetRoutesConsumptionRequest struct {
-	Imo     int             `json:"imo" validate:"required"`
-	Draught float64         `json:"draught" validate:"required"`
-	Routes  []*domain.Route `json:"routes" validate:"required"`
+	Imo           int             `json:"imo" validate:"required"`
+	Draught       float64         `json:"draught" validate:"required"`
+	Routes        []*domain.Route `json:"routes" validate:"required"`
+	DistanceModel string          `json:"distanceModel" validate:"omitempty,oneof=haversine vincenty"`
+	Eco           bool            `json:"eco"`
+	FuelType      string          `json:"fuelType"`
+	ETSYear       int             `json:"etsYear" validate:"omitempty,gte=2024"`
+	MaxSpeed      float64         `json:"maxSpeed" validate:"omitempty,gt=0"`
+}
+
+// Options returns the optional calculation settings of the request
+func (r *GetRoutesConsumptionRequest) Options() domain.ConsumptionOptions {
+	return domain.ConsumptionOptions{
+		DistanceModel:  r.DistanceModel,
+		Eco:            r.Eco,
+		FuelType:       r.FuelType,
+		ETSYear:        r.ETSYear,
+		MaxSpeedInKnot: r.MaxSpeed,
+	}
+}
+
+// BatchConsumptionRequest holds the entries of a batch consumption request, results are keyed by entry id
+type BatchConsumptionRequest struct {
+	Entries []*BatchEntryRequest `json:"entries" validate:"required,min=1,max=500,dive,required"`
+}
+
+// BatchEntryRequest is a routes consumption request of a single vessel identified by id
+type BatchEntryRequest struct {
+	ID string `json:"id" validate:"required"`
+	GetRoutesConsumptionRequest
+}
+
+// BatchEntries returns the entries of the request
+func (r *BatchConsumptionRequest) BatchEntries() []*domain.BatchEntry {
+	entries := make([]*domain.BatchEntry, 0, len(r.Entries))
+	for _, e := range r.Entries {
+		entries = append(entries, &domain.BatchEntry{
+			ID:      e.ID,
+			IMO:     e.Imo,
+			Draught: e.Draught,
+			Routes:  e.Routes,
+			Options: e.Options(),
+		})
+	}
+	return entries
+}
+
+// ConsumptionQuery holds the query params of the routes consumption request
+type ConsumptionQuery struct {
+	Detail string `query:"detail" validate:"omitempty,oneof=legs"`
+}
+
+// SaveVesselRequest holds the particulars of a vessel to register.
+// Design speed is in knots, auxiliary consumptions are in metric tons per day, config defaults are used when omitted.
+type SaveVesselRequest struct {
+	Name                   string   `json:"name" validate:"required,max=128"`
+	ShipType               string   `json:"shipType" validate:"required,oneof=bulk_carrier gas_carrier tanker container_ship general_cargo_ship refrigerated_cargo_carrier combination_carrier lng_carrier ro_ro_cargo_ship"`
+	DWT                    float64  `json:"dwt" validate:"required,gt=0"`
+	GT                     *float64 `json:"gt" validate:"omitempty,gt=0"`
+	MainFuel               string   `json:"mainFuel"`
+	DesignSpeed            *float64 `json:"designSpeed" validate:"omitempty,gt=0"`
+	ManoeuvringConsumption *float64 `json:"manoeuvringConsumption" validate:"omitempty,gte=0"`
+	AnchoredConsumption    *float64 `json:"anchoredConsumption" validate:"omitempty,gte=0"`
+	BerthedConsumption     *float64 `json:"berthedConsumption" validate:"omitempty,gte=0"`
+}
+
+// Vessel returns the particulars of the request as the vessel with given imo
+func (r *SaveVesselRequest) Vessel(imo int) *domain.Vessel {
+	vessel := &domain.Vessel{
+		IMO:                    imo,
+		Name:                   r.Name,
+		ShipType:               domain.ShipType(r.ShipType),
+		DWT:                    r.DWT,
+		GT:                     r.GT,
+		DesignSpeed:            r.DesignSpeed,
+		ManoeuvringConsumption: r.ManoeuvringConsumption,
+		AnchoredConsumption:    r.AnchoredConsumption,
+		BerthedConsumption:     r.BerthedConsumption,
+	}
+	if r.MainFuel != "" {
+		mainFuel := domain.FuelType(r.MainFuel)
+		vessel.MainFuel = &mainFuel
+	}
+	return vessel
+}
+
+// FuelTableRequest holds the rows of an uploaded fuel table, they are saved as a version of the vessel fuel table
+type FuelTableRequest struct {
+	Rows []*FuelTableRowRequest `json:"rows" validate:"required,min=1,max=100000,dive,required"`
+}
+
+// FuelTableRowRequest is a fuel table row, consumption in metric tons per day at the draught, speed and beaufort.
+// Rows with a wind sector only apply to wind from that sector, imo is optional and must match the vessel.
+type FuelTableRowRequest struct {
+	Imo         *int    `json:"imo"`
+	Draught     float64 `json:"draught" validate:"gt=0"`
+	Speed       float64 `json:"speed" validate:"gte=0"`
+	Beaufort    float64 `json:"beaufort" validate:"gte=0,lte=12"`
+	Consumption float64 `json:"consumption" validate:"gte=0"`
+	WindSector  string  `json:"windSector" validate:"omitempty,oneof=head beam following"`
+}
+
+// FuelMaps returns the rows of the request as fuel map rows of the vessel
+func (r *FuelTableRequest) FuelMaps(imo int) []*domain.FuelMap {
+	rows := make([]*domain.FuelMap, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		fuelMap := &domain.FuelMap{
+			VesselId:   imo,
+			Draught:    row.Draught,
+			Speed:      row.Speed,
+			Weather:    row.Beaufort,
+			Consumtion: row.Consumption,
+		}
+		if row.Imo != nil {
+			fuelMap.VesselId = *row.Imo
+		}
+		if row.WindSector != "" {
+			sector := domain.WindSector(row.WindSector)
+			fuelMap.WindSector = &sector
+		}
+		rows = append(rows, fuelMap)
+	}
+	return rows
+}
+
+// FuelTableQuery holds the query params of the fuel table download,
+// At selects the version in effect at that time and defaults to now
+type FuelTableQuery struct {
+	Format string    `query:"format" validate:"omitempty,oneof=csv json"`
+	At     time.Time `query:"at"`
+}
+
+// FuelTableUploadQuery holds the query params of the fuel table upload,
+// without ValidFrom the rows replace the latest version of the fuel table
+type FuelTableUploadQuery struct {
+	ValidFrom time.Time `query:"validFrom"`
+}
+
+// VersionStart returns the start of the uploaded version, nil when not given
+func (q *FuelTableUploadQuery) VersionStart() *time.Time {
+	if q.ValidFrom.IsZero() {
+		return nil
+	}
+	validFrom := q.ValidFrom
+	return &validFrom
+}
+
+// FuelTableQualityQuery holds the query params of the fuel table quality report,
+// At selects the version in effect at that time and defaults to now
+type FuelTableQualityQuery struct {
+	At time.Time `query:"at"`
+}
+
+// CurvesQuery holds the query params of the consumption curves request,
+// At selects the fuel table version in effect at that time and defaults to now
+type CurvesQuery struct {
+	At time.Time `query:"at"`
+}
+
+// atOrNow returns at, or the current time when at is not set
+func atOrNow(at time.Time) time.Time {
+	if at.IsZero() {
+		return time.Now()
+	}
+	return at
+}
+
+// GetCIIRequest holds the routes sailed by a vessel during the reporting year
+type GetCIIRequest struct {
+	Year          int             `json:"year" validate:"required"`
+	Draught       float64         `json:"draught" validate:"required"`
+	Routes        []*domain.Route `json:"routes" validate:"required"`
+	DistanceModel string          `json:"distanceModel" validate:"omitempty,oneof=haversine vincenty"`
+	FuelType      string          `json:"fuelType"`
+}
+
+// Options returns the optional calculation settings of the request
+func (r *GetCIIRequest) Options() domain.ConsumptionOptions {
+	return domain.ConsumptionOptions{
+		DistanceModel: r.DistanceModel,
+		FuelType:      r.FuelType,
+	}
+}
+
+// GetFuelEUBalanceRequest holds the routes sailed by a vessel during the reporting year
+// and the mass share of every fuel burned
+type GetFuelEUBalanceRequest struct {
+	Year          int                `json:"year" validate:"required,gte=2025"`
+	Draught       float64            `json:"draught" validate:"required"`
+	Routes        []*domain.Route    `json:"routes" validate:"required"`
+	DistanceModel string             `json:"distanceModel" validate:"omitempty,oneof=haversine vincenty"`
+	FuelType      string             `json:"fuelType"`
+	FuelMix       map[string]float64 `json:"fuelMix" validate:"omitempty,dive,gt=0,lte=1"`
+}
+
+// Options returns the optional calculation settings of the request
+func (r *GetFuelEUBalanceRequest) Options() domain.ConsumptionOptions {
+	return domain.ConsumptionOptions{
+		DistanceModel: r.DistanceModel,
+		FuelType:      r.FuelType,
+	}
+}
+
+// OptimiseSpeedRequest holds the planned voyage the speed profile is calculated for
+type OptimiseSpeedRequest struct {
+	Draught       float64           `json:"draught" validate:"required"`
+	Departure     time.Time         `json:"departure" validate:"required"`
+	Arrival       time.Time         `json:"arrival" validate:"required,gtfield=Departure"`
+	Waypoints     []domain.Waypoint `json:"waypoints" validate:"required,min=2"`
+	DistanceModel string            `json:"distanceModel" validate:"omitempty,oneof=haversine vincenty"`
+	FuelType      string            `json:"fuelType"`
+}
+
+// Options returns the optional calculation settings of the request
+func (r *OptimiseSpeedRequest) Options() domain.ConsumptionOptions {
+	return domain.ConsumptionOptions{
+		DistanceModel: r.DistanceModel,
+		FuelType:      r.FuelType,
+	}
+}
+
+// PlanVoyageRequest holds the waypoints of a planned voyage and the speed they are sailed at,
+// either a constant speed or one speed per leg
+type PlanVoyageRequest struct {
+	Draught       float64           `json:"draught" validate:"required"`
+	Departure     time.Time         `json:"departure" validate:"required"`
+	Waypoints     []domain.Waypoint `json:"waypoints" validate:"required,min=2"`
+	Speed         float64           `json:"speed" validate:"required_without=LegSpeeds,omitempty,gt=0"`
+	LegSpeeds     []float64         `json:"legSpeeds" validate:"required_without=Speed,omitempty,dive,gt=0"`
+	DistanceModel string            `json:"distanceModel" validate:"omitempty,oneof=haversine vincenty"`
+	Eco           bool              `json:"eco"`
+	FuelType      string            `json:"fuelType"`
+	ETSYear       int               `json:"etsYear" validate:"omitempty,gte=2024"`
+}
+
+// Speeds returns the leg speeds, or the constant speed when no leg speeds are given
+func (r *PlanVoyageRequest) Speeds() []float64 {
+	if len(r.LegSpeeds) > 0 {
+		return r.LegSpeeds
+	}
+	return []float64{r.Speed}
+}
+
+// Options returns the optional calculation settings of the request
+func (r *PlanVoyageRequest) Options() domain.ConsumptionOptions {
+	return domain.ConsumptionOptions{
+		DistanceModel: r.DistanceModel,
+		Eco:           r.Eco,
+		FuelType:      r.FuelType,
+		ETSYear:       r.ETSYear,
+	}
 }
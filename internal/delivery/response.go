@@ -1,19 +1,482 @@
 package delivery
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// DetailLegs is the value of the detail query param that adds the per leg breakdown to the response
+const DetailLegs = "legs"
+
 type RouteConsumptionResponse struct {
+	ConsumtionInMetricTons float64           `json:"ConsumtionInMetricTons"`
+	ConsumptionInCO2       float64           `json:"ConsumptionInCO2"`
+	FuelType               string            `json:"FuelType"`
+	DraughtLayers          []float64         `json:"DraughtLayers"`
+	Confidence             float64           `json:"Confidence"`
+	Warnings               []WarningResponse `json:"Warnings"`
+	Legs                   []*LegResponse    `json:"Legs,omitempty"`
+	Eco                    *EcoResponse      `json:"Eco,omitempty"`
+	ETS                    *ETSResponse      `json:"ETS,omitempty"`
+	Cleaning               *CleaningResponse `json:"Cleaning,omitempty"`
+}
+
+// WarningResponse is an issue that lowers the quality of the route result, Legs are indexes of the affected legs
+type WarningResponse struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+	Legs    []int  `json:"Legs,omitempty"`
+}
+
+// CleaningResponse reports the datapoints reordered and removed before the calculation
+type CleaningResponse struct {
+	OutOfOrder int                     `json:"OutOfOrder"`
+	Removed    []*RemovedPointResponse `json:"Removed"`
+}
+
+// RemovedPointResponse is a datapoint removed before the calculation with the reason it was removed
+type RemovedPointResponse struct {
+	Point  domain.RouteData `json:"Point"`
+	Reason string           `json:"Reason"`
+}
+
+// ETSResponse is the EU ETS exposure of the route, CO2 in tonnes and PhaseIn in %
+type ETSResponse struct {
+	Year       int     `json:"Year"`
+	IntraEUCO2 float64 `json:"IntraEUCO2"`
+	ExtraEUCO2 float64 `json:"ExtraEUCO2"`
+	CO2InScope float64 `json:"CO2InScope"`
+	PhaseIn    float64 `json:"PhaseIn"`
+	EUAs       float64 `json:"EUAs"`
+}
+
+// EcoResponse is the consumption of the route at its fuel optimal constant speed
+type EcoResponse struct {
+	EcoSpeedInKnot         float64 `json:"EcoSpeedInKnot"`
 	ConsumtionInMetricTons float64 `json:"ConsumtionInMetricTons"`
 	ConsumptionInCO2       float64 `json:"ConsumptionInCO2"`
+	SavingInMetricTons     float64 `json:"SavingInMetricTons"`
+	SavingInCO2            float64 `json:"SavingInCO2"`
+}
+
+// LegResponse is the consumption breakdown of a single leg between 2 route datapoints
+type LegResponse struct {
+	SourceDate           time.Time               `json:"SourceDate"`
+	DestinationDate      time.Time               `json:"DestinationDate"`
+	State                string                  `json:"State"`
+	DistanceInNM         float64                 `json:"DistanceInNM"`
+	AvgSpeedInKnot       float64                 `json:"AvgSpeedInKnot"`
+	HeadingInDegrees     float64                 `json:"HeadingInDegrees"`
+	AvgWeatherInBeaufort float64                 `json:"AvgWeatherInBeaufort"`
+	AvgWindSpeedInKnot   float64                 `json:"AvgWindSpeedInKnot"`
+	WindDirection        *float64                `json:"WindDirection,omitempty"`
+	WindSector           string                  `json:"WindSector,omitempty"`
+	AvgWaveHeight        float64                 `json:"AvgWaveHeightInMeters"`
+	FuelTableRows        []*FuelTableRowResponse `json:"FuelTableRows"`
+	AvgDailyConsumtion   float64                 `json:"AvgDailyConsumtion"`
+	ExactConsumtion      float64                 `json:"ExactConsumtion"`
+	WeatherMissing       bool                    `json:"WeatherMissing"`
+	Confidence           float64                 `json:"Confidence"`
+}
+
+// FuelTableRowResponse is a fuel table row used to interpolate leg consumption
+type FuelTableRowResponse struct {
+	Draught     float64 `json:"Draught"`
+	Speed       float64 `json:"Speed"`
+	Beaufort    float64 `json:"Beaufort"`
+	Consumption float64 `json:"Consumption"`
+	WindSector  string  `json:"WindSector,omitempty"`
+}
+
+// FuelTableResponse holds all rows of a vessel fuel table
+type FuelTableResponse struct {
+	IMO  int                     `json:"Imo"`
+	Rows []*FuelTableRowResponse `json:"Rows"`
+}
+
+// FuelTableSummaryResponse describes a version of the fuel table of a vessel
+type FuelTableSummaryResponse struct {
+	IMO         int        `json:"Imo"`
+	VersionID   uuid.UUID  `json:"VersionId"`
+	ValidFrom   *time.Time `json:"ValidFrom"`
+	ValidTo     *time.Time `json:"ValidTo"`
+	Rows        int        `json:"Rows"`
+	Draughts    []float64  `json:"Draughts"`
+	MinSpeed    float64    `json:"MinSpeed"`
+	MaxSpeed    float64    `json:"MaxSpeed"`
+	MinBeaufort float64    `json:"MinBeaufort"`
+	MaxBeaufort float64    `json:"MaxBeaufort"`
+}
+
+// FuelTableQualityResponse is the data quality report of a fuel table version
+type FuelTableQualityResponse struct {
+	FuelTableSummaryResponse
+	Issues []FuelTableIssueResponse `json:"Issues"`
+}
+
+// FuelTableIssueResponse is an issue found on a fuel table, Rows are indexes of the affected rows in the table
+type FuelTableIssueResponse struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+	Rows    []int  `json:"Rows"`
 }
 
-func NewResponseView(consumtions []float64) []RouteConsumptionResponse {
+func NewResponseView(consumtions []*domain.RouteConsumption, withLegs bool) []RouteConsumptionResponse {
 	allRoutesConsumption := []RouteConsumptionResponse{}
 
 	for _, consumtion := range consumtions {
 		r := RouteConsumptionResponse{
-			ConsumtionInMetricTons: consumtion,
-			ConsumptionInCO2:       consumtion * float64(3.114),
+			ConsumtionInMetricTons: consumtion.ConsumtionInMetricTons,
+			ConsumptionInCO2:       consumtion.ConsumptionInCO2,
+			FuelType:               string(consumtion.FuelType),
+			DraughtLayers:          consumtion.DraughtLayers,
+			Confidence:             consumtion.Confidence,
+			Warnings:               make([]WarningResponse, 0, len(consumtion.Warnings)),
+		}
+		for _, w := range consumtion.Warnings {
+			r.Warnings = append(r.Warnings, WarningResponse{Code: string(w.Code), Message: w.Message, Legs: w.Legs})
+		}
+		if withLegs {
+			r.Legs = newLegsView(consumtion.Legs)
+		}
+		if consumtion.Eco != nil {
+			r.Eco = &EcoResponse{
+				EcoSpeedInKnot:         consumtion.Eco.SpeedInKnot,
+				ConsumtionInMetricTons: consumtion.Eco.ConsumtionInMetricTons,
+				ConsumptionInCO2:       consumtion.Eco.ConsumptionInCO2,
+				SavingInMetricTons:     consumtion.Eco.SavingInMetricTons,
+				SavingInCO2:            consumtion.Eco.SavingInCO2,
+			}
+		}
+		if consumtion.Cleaning != nil && (consumtion.Cleaning.OutOfOrder > 0 || len(consumtion.Cleaning.Removed) > 0) {
+			r.Cleaning = newCleaningView(consumtion.Cleaning)
+		}
+		if consumtion.ETS != nil {
+			r.ETS = &ETSResponse{
+				Year:       consumtion.ETS.Year,
+				IntraEUCO2: consumtion.ETS.IntraEUCO2,
+				ExtraEUCO2: consumtion.ETS.ExtraEUCO2,
+				CO2InScope: consumtion.ETS.CO2InScope,
+				PhaseIn:    consumtion.ETS.PhaseIn,
+				EUAs:       consumtion.ETS.EUAs,
+			}
 		}
 		allRoutesConsumption = append(allRoutesConsumption, r)
 	}
 	return allRoutesConsumption
 }
+
+// BatchResultResponse is the result of a batch entry, either its routes consumption or the error it failed with
+type BatchResultResponse struct {
+	Routes []RouteConsumptionResponse `json:"Routes,omitempty"`
+	Error  RestErr                    `json:"Error,omitempty"`
+}
+
+func NewBatchView(results map[string]*domain.BatchResult, withLegs bool) map[string]*BatchResultResponse {
+	batch := make(map[string]*BatchResultResponse, len(results))
+	for id, result := range results {
+		if result.Err != nil {
+			batch[id] = &BatchResultResponse{Error: ParseErrors(result.Err)}
+			continue
+		}
+		batch[id] = &BatchResultResponse{Routes: NewResponseView(result.Routes, withLegs)}
+	}
+	return batch
+}
+
+func NewFuelTableView(imo int, fuelMaps []*domain.FuelMap) *FuelTableResponse {
+	rows := make([]*FuelTableRowResponse, 0, len(fuelMaps))
+	for _, fm := range fuelMaps {
+		row := &FuelTableRowResponse{
+			Draught:     fm.Draught,
+			Speed:       fm.Speed,
+			Beaufort:    fm.Weather,
+			Consumption: fm.Consumtion,
+		}
+		if fm.WindSector != nil {
+			row.WindSector = string(*fm.WindSector)
+		}
+		rows = append(rows, row)
+	}
+	return &FuelTableResponse{IMO: imo, Rows: rows}
+}
+
+func NewFuelTableSummaryView(summary *domain.FuelTableSummary) *FuelTableSummaryResponse {
+	return &FuelTableSummaryResponse{
+		IMO:         summary.IMO,
+		VersionID:   summary.ID,
+		ValidFrom:   summary.ValidFrom,
+		ValidTo:     summary.ValidTo,
+		Rows:        summary.Rows,
+		Draughts:    summary.Draughts,
+		MinSpeed:    summary.MinSpeed,
+		MaxSpeed:    summary.MaxSpeed,
+		MinBeaufort: summary.MinBeaufort,
+		MaxBeaufort: summary.MaxBeaufort,
+	}
+}
+
+func NewFuelTableQualityView(quality *domain.FuelTableQuality) *FuelTableQualityResponse {
+	view := &FuelTableQualityResponse{
+		FuelTableSummaryResponse: *NewFuelTableSummaryView(&quality.FuelTableSummary),
+		Issues:                   make([]FuelTableIssueResponse, 0, len(quality.Issues)),
+	}
+	for _, issue := range quality.Issues {
+		view.Issues = append(view.Issues, FuelTableIssueResponse{Code: string(issue.Code), Message: issue.Message, Rows: issue.Rows})
+	}
+	return view
+}
+
+func NewFuelTableSummariesView(summaries []*domain.FuelTableSummary) []*FuelTableSummaryResponse {
+	views := make([]*FuelTableSummaryResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		views = append(views, NewFuelTableSummaryView(summary))
+	}
+	return views
+}
+
+func newCleaningView(cleaning *domain.RouteCleaning) *CleaningResponse {
+	removed := make([]*RemovedPointResponse, 0, len(cleaning.Removed))
+	for _, rp := range cleaning.Removed {
+		removed = append(removed, &RemovedPointResponse{Point: rp.Point, Reason: rp.Reason})
+	}
+	return &CleaningResponse{
+		OutOfOrder: cleaning.OutOfOrder,
+		Removed:    removed,
+	}
+}
+
+func newLegsView(pointToPoints []*domain.PointToPoint) []*LegResponse {
+	legs := make([]*LegResponse, 0, len(pointToPoints))
+
+	for _, ptp := range pointToPoints {
+		rows := make([]*FuelTableRowResponse, 0, len(ptp.FuelMapRows))
+		for _, fm := range ptp.FuelMapRows {
+			row := &FuelTableRowResponse{
+				Draught:     fm.Draught,
+				Speed:       fm.Speed,
+				Beaufort:    fm.Weather,
+				Consumption: fm.Consumtion,
+			}
+			if fm.WindSector != nil {
+				row.WindSector = string(*fm.WindSector)
+			}
+			rows = append(rows, row)
+		}
+		legs = append(legs, &LegResponse{
+			SourceDate:           ptp.Source.Date,
+			DestinationDate:      ptp.Destination.Date,
+			State:                string(ptp.State),
+			DistanceInNM:         ptp.DistanceInNM,
+			AvgSpeedInKnot:       ptp.AvgSpeedInKnot,
+			HeadingInDegrees:     ptp.HeadingInDegrees,
+			AvgWeatherInBeaufort: ptp.AvgWeatherInBeaufort,
+			AvgWindSpeedInKnot:   ptp.AvgWindSpeedInKnot,
+			WindDirection:        ptp.WindDirection,
+			WindSector:           string(ptp.WindSector),
+			AvgWaveHeight:        ptp.AvgWaveHeightInMeters,
+			FuelTableRows:        rows,
+			AvgDailyConsumtion:   ptp.AvgDailyConsumtion,
+			ExactConsumtion:      ptp.ExactConsumtion,
+			WeatherMissing:       ptp.WeatherMissing,
+			Confidence:           ptp.Confidence,
+		})
+	}
+	return legs
+}
+
+// VesselResponse holds the particulars of a registered vessel
+type VesselResponse struct {
+	IMO                    int      `json:"Imo"`
+	Name                   string   `json:"Name"`
+	ShipType               string   `json:"ShipType"`
+	DWT                    float64  `json:"Dwt"`
+	GT                     *float64 `json:"Gt,omitempty"`
+	MainFuel               string   `json:"MainFuel,omitempty"`
+	DesignSpeed            *float64 `json:"DesignSpeed,omitempty"`
+	ManoeuvringConsumption *float64 `json:"ManoeuvringConsumption,omitempty"`
+	AnchoredConsumption    *float64 `json:"AnchoredConsumption,omitempty"`
+	BerthedConsumption     *float64 `json:"BerthedConsumption,omitempty"`
+}
+
+func NewVesselView(vessel *domain.Vessel) *VesselResponse {
+	view := &VesselResponse{
+		IMO:                    vessel.IMO,
+		Name:                   vessel.Name,
+		ShipType:               string(vessel.ShipType),
+		DWT:                    vessel.DWT,
+		GT:                     vessel.GT,
+		DesignSpeed:            vessel.DesignSpeed,
+		ManoeuvringConsumption: vessel.ManoeuvringConsumption,
+		AnchoredConsumption:    vessel.AnchoredConsumption,
+		BerthedConsumption:     vessel.BerthedConsumption,
+	}
+	if vessel.MainFuel != nil {
+		view.MainFuel = string(*vessel.MainFuel)
+	}
+	return view
+}
+
+func NewVesselsView(vessels []*domain.Vessel) []*VesselResponse {
+	views := make([]*VesselResponse, 0, len(vessels))
+	for _, vessel := range vessels {
+		views = append(views, NewVesselView(vessel))
+	}
+	return views
+}
+
+// CIIResponse is the carbon intensity rating of a vessel for a year, CII values in gCO2/dwt-nm
+type CIIResponse struct {
+	Imo              int        `json:"Imo"`
+	Year             int        `json:"Year"`
+	ShipType         string     `json:"ShipType"`
+	Capacity         float64    `json:"Capacity"`
+	DistanceInNM     float64    `json:"DistanceInNM"`
+	ConsumptionInCO2 float64    `json:"ConsumptionInCO2"`
+	AttainedCII      float64    `json:"AttainedCII"`
+	ReferenceCII     float64    `json:"ReferenceCII"`
+	ReductionFactor  float64    `json:"ReductionFactor"`
+	RequiredCII      float64    `json:"RequiredCII"`
+	Boundaries       [4]float64 `json:"Boundaries"`
+	Rating           string     `json:"Rating"`
+}
+
+func NewCIIView(rating *domain.CIIRating) *CIIResponse {
+	return &CIIResponse{
+		Imo:              rating.IMO,
+		Year:             rating.Year,
+		ShipType:         string(rating.ShipType),
+		Capacity:         rating.Capacity,
+		DistanceInNM:     rating.DistanceInNM,
+		ConsumptionInCO2: rating.ConsumptionInCO2,
+		AttainedCII:      rating.AttainedCII,
+		ReferenceCII:     rating.ReferenceCII,
+		ReductionFactor:  rating.ReductionFactor,
+		RequiredCII:      rating.RequiredCII,
+		Boundaries:       rating.Boundaries,
+		Rating:           rating.Rating,
+	}
+}
+
+// FuelEUResponse is the FuelEU Maritime compliance of a vessel for a year.
+// Intensities are in gCO2e/MJ, ComplianceBalance in gCO2e and Penalty in EUR.
+type FuelEUResponse struct {
+	Year              int                        `json:"Year"`
+	Fuels             []*FuelConsumptionResponse `json:"Fuels"`
+	EnergyInMJ        float64                    `json:"EnergyInMJ"`
+	WtTIntensity      float64                    `json:"WtTIntensity"`
+	TtWIntensity      float64                    `json:"TtWIntensity"`
+	GHGIntensity      float64                    `json:"GHGIntensity"`
+	TargetIntensity   float64                    `json:"TargetIntensity"`
+	ComplianceBalance float64                    `json:"ComplianceBalance"`
+	Penalty           float64                    `json:"Penalty"`
+}
+
+// FuelConsumptionResponse is the amount of a fuel burned
+type FuelConsumptionResponse struct {
+	FuelType               string  `json:"FuelType"`
+	ConsumtionInMetricTons float64 `json:"ConsumtionInMetricTons"`
+}
+
+func NewFuelEUView(balance *domain.FuelEUBalance) *FuelEUResponse {
+	fuels := make([]*FuelConsumptionResponse, 0, len(balance.Fuels))
+	for _, fc := range balance.Fuels {
+		fuels = append(fuels, &FuelConsumptionResponse{
+			FuelType:               string(fc.FuelType),
+			ConsumtionInMetricTons: fc.ConsumtionInMetricTons,
+		})
+	}
+	return &FuelEUResponse{
+		Year:              balance.Year,
+		Fuels:             fuels,
+		EnergyInMJ:        balance.EnergyInMJ,
+		WtTIntensity:      balance.WtTIntensity,
+		TtWIntensity:      balance.TtWIntensity,
+		GHGIntensity:      balance.GHGIntensity,
+		TargetIntensity:   balance.TargetIntensity,
+		ComplianceBalance: balance.ComplianceBalance,
+		Penalty:           balance.Penalty,
+	}
+}
+
+// SpeedProfileResponse is the fuel optimal speed per leg of a planned voyage
+type SpeedProfileResponse struct {
+	Departure                           time.Time      `json:"Departure"`
+	RequiredArrival                     time.Time      `json:"RequiredArrival"`
+	Arrival                             time.Time      `json:"Arrival"`
+	FuelType                            string         `json:"FuelType"`
+	DistanceInNM                        float64        `json:"DistanceInNM"`
+	ConsumtionInMetricTons              float64        `json:"ConsumtionInMetricTons"`
+	ConsumptionInCO2                    float64        `json:"ConsumptionInCO2"`
+	ConstantSpeedInKnot                 float64        `json:"ConstantSpeedInKnot"`
+	ConstantSpeedConsumtionInMetricTons float64        `json:"ConstantSpeedConsumtionInMetricTons"`
+	Legs                                []*LegResponse `json:"Legs"`
+}
+
+func NewSpeedProfileView(profile *domain.SpeedProfile) *SpeedProfileResponse {
+	return &SpeedProfileResponse{
+		Departure:                           profile.Departure,
+		RequiredArrival:                     profile.RequiredArrival,
+		Arrival:                             profile.Arrival,
+		FuelType:                            string(profile.FuelType),
+		DistanceInNM:                        profile.DistanceInNM,
+		ConsumtionInMetricTons:              profile.ConsumtionInMetricTons,
+		ConsumptionInCO2:                    profile.ConsumptionInCO2,
+		ConstantSpeedInKnot:                 profile.ConstantSpeedInKnot,
+		ConstantSpeedConsumtionInMetricTons: profile.ConstantSpeedConsumtionInMetricTons,
+		Legs:                                newLegsView(profile.Legs),
+	}
+}
+
+// PlannedVoyageResponse is the ETA and consumption of a planned voyage
+type PlannedVoyageResponse struct {
+	Departure    time.Time                `json:"Departure"`
+	Arrival      time.Time                `json:"Arrival"`
+	DistanceInNM float64                  `json:"DistanceInNM"`
+	Consumption  RouteConsumptionResponse `json:"Consumption"`
+}
+
+func NewPlannedVoyageView(voyage *domain.PlannedVoyage) *PlannedVoyageResponse {
+	return &PlannedVoyageResponse{
+		Departure:    voyage.Departure,
+		Arrival:      voyage.Arrival,
+		DistanceInNM: voyage.DistanceInNM,
+		Consumption:  NewResponseView([]*domain.RouteConsumption{voyage.Route}, true)[0],
+	}
+}
+
+// CurveResponse is the fitted consumption curve of a draught:
+// consumption = Coefficient * speed ^ Exponent + WeatherCoefficient * beaufort
+type CurveResponse struct {
+	Draught            float64 `json:"Draught"`
+	Coefficient        float64 `json:"Coefficient"`
+	Exponent           float64 `json:"Exponent"`
+	WeatherCoefficient float64 `json:"WeatherCoefficient"`
+	RSquared           float64 `json:"RSquared"`
+	Rows               int     `json:"Rows"`
+	MinSpeed           float64 `json:"MinSpeed"`
+	MaxSpeed           float64 `json:"MaxSpeed"`
+	MinBeaufort        float64 `json:"MinBeaufort"`
+	MaxBeaufort        float64 `json:"MaxBeaufort"`
+}
+
+func NewCurvesView(curves []*domain.ConsumptionCurve) []*CurveResponse {
+	view := make([]*CurveResponse, 0, len(curves))
+	for _, curve := range curves {
+		view = append(view, &CurveResponse{
+			Draught:            curve.Draught,
+			Coefficient:        curve.Coefficient,
+			Exponent:           curve.Exponent,
+			WeatherCoefficient: curve.WeatherCoefficient,
+			RSquared:           curve.RSquared,
+			Rows:               curve.Rows,
+			MinSpeed:           curve.MinSpeed,
+			MaxSpeed:           curve.MaxSpeed,
+			MinBeaufort:        curve.MinWeather,
+			MaxBeaufort:        curve.MaxWeather,
+		})
+	}
+	return view
+}
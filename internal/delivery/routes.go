@@ -4,4 +4,35 @@ import "github.com/labstack/echo/v4"
 
 func MapVesselRoutes(vesselsGroup *echo.Group, h VesselsHandlers) {
 	vesselsGroup.POST("", h.GetRoutesConsumtion())
+	vesselsGroup.POST("/batch", h.GetBatchConsumption())
+	vesselsGroup.GET("/:imo/curves", h.GetConsumptionCurves())
+}
+
+func MapRegistryRoutes(vesselsGroup *echo.Group, h RegistryHandlers) {
+	vesselsGroup.GET("", h.ListVessels())
+	vesselsGroup.GET("/:imo", h.GetVessel())
+	vesselsGroup.PUT("/:imo", h.SaveVessel())
+	vesselsGroup.DELETE("/:imo", h.DeleteVessel())
+}
+
+func MapCIIRoutes(vesselsGroup *echo.Group, h CIIHandlers) {
+	vesselsGroup.POST("/:imo/cii", h.GetCIIRating())
+}
+
+func MapFuelEURoutes(vesselsGroup *echo.Group, h FuelEUHandlers) {
+	vesselsGroup.POST("/:imo/fueleu", h.GetFuelEUBalance())
+}
+
+func MapVoyageRoutes(vesselsGroup *echo.Group, h VoyageHandlers) {
+	vesselsGroup.POST("/:imo/speed-profile", h.OptimiseSpeed())
+	vesselsGroup.POST("/:imo/plan", h.PlanVoyage())
+}
+
+func MapFuelTableRoutes(vesselsGroup *echo.Group, h FuelTableHandlers) {
+	vesselsGroup.GET("/fuel-tables", h.ListFuelTables())
+	vesselsGroup.GET("/:imo/fuel-table", h.GetFuelTable())
+	vesselsGroup.GET("/:imo/fuel-table/versions", h.GetFuelTableVersions())
+	vesselsGroup.GET("/:imo/fuel-table/quality", h.GetFuelTableQuality())
+	vesselsGroup.PUT("/:imo/fuel-table", h.UploadFuelTable())
+	vesselsGroup.DELETE("/:imo/fuel-table", h.DeleteFuelTable())
 }
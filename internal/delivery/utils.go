@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"strconv"
 
 	"time"
 
@@ -77,6 +78,28 @@ func ReadRequest(ctx echo.Context, request interface{}) error {
 	return validate.StructCtx(ctx.Request().Context(), request)
 }
 
+// Read imo path param
+func GetIMOParam(ctx echo.Context) (int, error) {
+	operation := errors.Op("utils.GetIMOParam")
+	imo, err := strconv.Atoi(ctx.Param("imo"))
+	if err != nil {
+		return 0, errors.E(operation, errors.KindBadInput, "imo must be a number")
+	}
+	return imo, nil
+}
+
+// Read and validate query params
+func ReadQuery(ctx echo.Context, query interface{}) error {
+	operation := errors.Op("utils.ReadQuery")
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, query); err != nil {
+		return errors.E(operation, errors.KindBadInput, err)
+	}
+	if err := validate.StructCtx(ctx.Request().Context(), query); err != nil {
+		return errors.E(operation, errors.KindBadInput, err)
+	}
+	return nil
+}
+
 // Read sanitize and validate request
 func SanitizeRequest(ctx echo.Context, request interface{}) error {
 	operation := errors.Op("utils.SanitizeRequest")
@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type VoyageHandlers interface {
+	OptimiseSpeed() echo.HandlerFunc
+	PlanVoyage() echo.HandlerFunc
+}
+
+type voyageHandlers struct {
+	cfg    *config.Config
+	vs     service.VoyageService
+	logger logger.Logger
+}
+
+// NewVoyageHandlers voyage handlers constructor
+func NewVoyageHandlers(cfg *config.Config, vs service.VoyageService, logger logger.Logger) VoyageHandlers {
+	return &voyageHandlers{cfg: cfg, vs: vs, logger: logger}
+}
+
+func (h voyageHandlers) OptimiseSpeed() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		req := &OptimiseSpeedRequest{}
+		if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		profile, err := h.vs.OptimiseSpeed(ctx, imo, req.Draught, req.Departure, req.Arrival, req.Waypoints, req.Options())
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewSpeedProfileView(profile))
+	}
+}
+
+func (h voyageHandlers) PlanVoyage() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := GetRequestCtx(c)
+
+		imo, err := GetIMOParam(c)
+		if err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		req := &PlanVoyageRequest{}
+		if err := SanitizeRequest(c, req); err != nil {
+			return ErrResponseWithLog(c, h.logger, err)
+		}
+
+		voyage, err := h.vs.PlanVoyage(ctx, imo, req.Draught, req.Departure, req.Waypoints, req.Speeds(), req.Options())
+		if err != nil {
+			LogResponseError(c, h.logger, err)
+			return c.JSON(ErrorResponse(err))
+		}
+		return c.JSON(http.StatusOK, NewPlannedVoyageView(voyage))
+	}
+}
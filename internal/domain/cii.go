@@ -0,0 +1,19 @@
+package domain
+
+// CIIRating is the IMO Carbon Intensity Indicator of a vessel for a reporting year.
+// CII values are annual efficiency ratios (AER) in gCO2/dwt-nm.
+type CIIRating struct {
+	IMO              int
+	Year             int
+	ShipType         ShipType
+	Capacity         float64
+	DistanceInNM     float64
+	ConsumptionInCO2 float64
+	AttainedCII      float64
+	ReferenceCII     float64
+	ReductionFactor  float64
+	RequiredCII      float64
+	// Boundaries are the upper limits of ratings A to D, anything above the last one is rated E
+	Boundaries [4]float64
+	Rating     string
+}
@@ -0,0 +1,76 @@
+package domain
+
+// ConsumptionOptions holds optional per request settings for the consumption calculation
+type ConsumptionOptions struct {
+	// DistanceModel is the name of the geodesy model used for leg distance, config default when empty
+	DistanceModel string
+	// Eco adds the eco speed advisory to every route
+	Eco bool
+	// FuelType is the fuel burned on the routes, config default when empty
+	FuelType string
+	// ETSYear adds the EU ETS exposure for the given year to every route when set
+	ETSYear int
+	// MaxSpeedInKnot is the speed above which datapoints are considered GPS jumps, config default when 0
+	MaxSpeedInKnot float64
+}
+
+// RouteConsumption holds the fuel consumption calculated for a single route
+type RouteConsumption struct {
+	ConsumtionInMetricTons float64
+	ConsumptionInCO2       float64
+	FuelType               FuelType
+	DraughtLayers          []float64
+	Legs                   []*PointToPoint
+	Eco                    *EcoAdvisory
+	ETS                    *ETSExposure
+	Cleaning               *RouteCleaning
+	// Confidence is the 0-1 consumption weighted confidence of the legs
+	Confidence float64
+	Warnings   []Warning
+}
+
+// EcoAdvisory holds the consumption of a route sailed at its fuel optimal constant speed
+type EcoAdvisory struct {
+	SpeedInKnot            float64
+	ConsumtionInMetricTons float64
+	ConsumptionInCO2       float64
+	SavingInMetricTons     float64
+	SavingInCO2            float64
+}
+
+// ETSExposure holds the EU ETS allowances needed for the emissions of a route
+type ETSExposure struct {
+	Year int
+	// IntraEUCO2 and ExtraEUCO2 are the tonnes of CO2 emitted on voyages between EU ports and from/to EU ports
+	IntraEUCO2 float64
+	ExtraEUCO2 float64
+	CO2InScope float64
+	PhaseIn    float64
+	EUAs       float64
+	// OutOfVoyageLegs are the indexes of the legs before the first or after the last port call, left out
+	OutOfVoyageLegs []int
+}
+
+// TotalDistanceInNM returns the distance sailed on all legs of the route
+func (rc *RouteConsumption) TotalDistanceInNM() float64 {
+	distance := 0.0
+	for _, ptp := range rc.Legs {
+		distance += ptp.DistanceInNM
+	}
+	return distance
+}
+
+// BatchEntry is one vessel of a batch consumption request, ID identifies its result
+type BatchEntry struct {
+	ID      string
+	IMO     int
+	Draught float64
+	Routes  []*Route
+	Options ConsumptionOptions
+}
+
+// BatchResult holds the route consumptions of a batch entry, or the error the entry failed with
+type BatchResult struct {
+	Routes []*RouteConsumption
+	Err    error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "math"
+
+// ConsumptionCurve is a regression fitted model of the daily consumption of a vessel at a draught:
+// consumption = Coefficient * speed ^ Exponent + WeatherCoefficient * beaufort
+type ConsumptionCurve struct {
+	Draught            float64
+	Coefficient        float64
+	Exponent           float64
+	WeatherCoefficient float64
+	// RSquared is the coefficient of determination of the fit on the fuel table rows
+	RSquared   float64
+	Rows       int
+	MinSpeed   float64
+	MaxSpeed   float64
+	MinWeather float64
+	MaxWeather float64
+}
+
+// Consumption returns the daily consumption in metric tons predicted for speed and weather
+func (c *ConsumptionCurve) Consumption(speed float64, weather float64) float64 {
+	return c.Coefficient*math.Pow(speed, c.Exponent) + c.WeatherCoefficient*weather
+}
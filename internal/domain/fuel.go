@@ -0,0 +1,56 @@
+package domain
+
+import "strings"
+
+// FuelType identifies the fuel burned by a vessel
+type FuelType string
+
+const (
+	FuelHFO      FuelType = "HFO"
+	FuelLFO      FuelType = "LFO"
+	FuelLSFO     FuelType = "LSFO"
+	FuelMGO      FuelType = "MGO"
+	FuelLNG      FuelType = "LNG"
+	FuelMethanol FuelType = "METHANOL"
+)
+
+// NormaliseFuelType makes fuel type lookups case insensitive
+func NormaliseFuelType(fuel string) FuelType {
+	return FuelType(strings.ToUpper(strings.TrimSpace(fuel)))
+}
+
+// GHGFactors are the well to wake emission factors of a fuel used by FuelEU Maritime
+type GHGFactors struct {
+	// LCV is the lower calorific value in MJ/g
+	LCV float64
+	// WtT factors are grams of gas emitted per MJ of fuel from well to tank
+	WtTCO2 float64
+	WtTCH4 float64
+	WtTN2O float64
+	// TtW factors are grams of gas emitted per gram of fuel burned
+	TtWCO2 float64
+	TtWCH4 float64
+	TtWN2O float64
+	// Slip is the % of fuel that leaves the engine unburnt as methane
+	Slip float64
+}
+
+// FuelConsumption is the amount of a fuel burned
+type FuelConsumption struct {
+	FuelType               FuelType
+	ConsumtionInMetricTons float64
+}
+
+// FuelEUBalance is the FuelEU Maritime GHG intensity and compliance of the fuels burned.
+// Intensities are in gCO2e/MJ, ComplianceBalance in gCO2e and Penalty in EUR.
+type FuelEUBalance struct {
+	Year              int
+	Fuels             []*FuelConsumption
+	EnergyInMJ        float64
+	WtTIntensity      float64
+	TtWIntensity      float64
+	GHGIntensity      float64
+	TargetIntensity   float64
+	ComplianceBalance float64
+	Penalty           float64
+}
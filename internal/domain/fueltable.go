@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FuelTableVersion is a fuel table of a vessel in effect from ValidFrom until ValidTo (excluded).
+// A nil ValidFrom is in effect since ever and a nil ValidTo until a newer version is uploaded.
+type FuelTableVersion struct {
+	ID        uuid.UUID  `db:"id"`
+	IMO       int        `db:"imo"`
+	ValidFrom *time.Time `db:"valid_from"`
+	ValidTo   *time.Time `db:"valid_to"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// InEffect tells if the version applies at t
+func (v *FuelTableVersion) InEffect(t time.Time) bool {
+	return (v.ValidFrom == nil || !t.Before(*v.ValidFrom)) && (v.ValidTo == nil || t.Before(*v.ValidTo))
+}
+
+// DistanceTo returns how long before or after the period of the version t is, 0 when in effect
+func (v *FuelTableVersion) DistanceTo(t time.Time) time.Duration {
+	switch {
+	case v.ValidFrom != nil && t.Before(*v.ValidFrom):
+		return v.ValidFrom.Sub(t)
+	case v.ValidTo != nil && !t.Before(*v.ValidTo):
+		return t.Sub(*v.ValidTo)
+	}
+	return 0
+}
+
+// FuelTableSummary describes a version of the fuel table of a vessel
+type FuelTableSummary struct {
+	FuelTableVersion
+	Rows        int
+	Draughts    []float64
+	MinSpeed    float64
+	MaxSpeed    float64
+	MinBeaufort float64
+	MaxBeaufort float64
+}
+
+// FuelTableIssueCode identifies the kind of issue found on the rows of a fuel table
+type FuelTableIssueCode string
+
+const (
+	IssueDuplicateCell FuelTableIssueCode = "duplicate_cell"
+	IssueNonMonotonic  FuelTableIssueCode = "non_monotonic"
+	IssueSpeedGap      FuelTableIssueCode = "speed_gap"
+	IssueBeaufortGap   FuelTableIssueCode = "beaufort_gap"
+	IssueOutlier       FuelTableIssueCode = "outlier"
+)
+
+// FuelTableIssue is an issue found on the rows of a fuel table.
+// Rows are the indexes of the affected rows in the table, empty when a whole layer is affected.
+type FuelTableIssue struct {
+	Code    FuelTableIssueCode
+	Message string
+	Rows    []int
+}
+
+// FuelTableQuality is the data quality report of a fuel table version, without issues for a clean table
+type FuelTableQuality struct {
+	FuelTableSummary
+	Issues []FuelTableIssue
+}
+
+// ValidateFuelTable checks the rows of an uploaded fuel table belong to the vessel and hold finite values
+func ValidateFuelTable(imo int, rows []*FuelMap) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("fuel table has no rows")
+	}
+	for i, row := range rows {
+		if row.VesselId != imo {
+			return fmt.Errorf("row %d: imo %d does not match vessel %d", i, row.VesselId, imo)
+		}
+		for _, v := range []float64{row.Draught, row.Speed, row.Weather, row.Consumtion} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("row %d: values must be finite", i)
+			}
+		}
+	}
+	return nil
+}
+
+// SummariseFuelTable returns the summary of the rows of a vessel fuel table version
+func SummariseFuelTable(version *FuelTableVersion, rows []*FuelMap) *FuelTableSummary {
+	summary := &FuelTableSummary{FuelTableVersion: *version, Rows: len(rows), Draughts: []float64{}}
+	if len(rows) == 0 {
+		return summary
+	}
+	summary.MinSpeed, summary.MaxSpeed = math.Inf(1), math.Inf(-1)
+	summary.MinBeaufort, summary.MaxBeaufort = math.Inf(1), math.Inf(-1)
+	seen := make(map[float64]struct{})
+	for _, row := range rows {
+		if _, exists := seen[row.Draught]; !exists {
+			seen[row.Draught] = struct{}{}
+			summary.Draughts = append(summary.Draughts, row.Draught)
+		}
+		summary.MinSpeed = math.Min(summary.MinSpeed, row.Speed)
+		summary.MaxSpeed = math.Max(summary.MaxSpeed, row.Speed)
+		summary.MinBeaufort = math.Min(summary.MinBeaufort, row.Weather)
+		summary.MaxBeaufort = math.Max(summary.MaxBeaufort, row.Weather)
+	}
+	sort.Float64s(summary.Draughts)
+	return summary
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kkr2/vessels/internal/geodesy"
+)
+
+// Waypoint is a planned position of a voyage without a timestamp
+type Waypoint struct {
+	Longitude float64   `json:"longitude"`
+	Latitude  float64   `json:"latitude"`
+	Port      *PortCall `json:"port,omitempty"`
+}
+
+// NormaliseWaypoints validates the coordinates of all waypoints and returns a copy with longitudes in [-180, 180)
+func NormaliseWaypoints(waypoints []Waypoint) ([]Waypoint, error) {
+	normalised := make([]Waypoint, len(waypoints))
+	for i, wp := range waypoints {
+		if err := geodesy.ValidateCoordinate(wp.Latitude, wp.Longitude); err != nil {
+			return nil, fmt.Errorf("waypoint %d: %w", i, err)
+		}
+		wp.Longitude = geodesy.NormaliseLongitude(wp.Longitude)
+		normalised[i] = wp
+	}
+	return normalised, nil
+}
+
+// LegDistances returns the distance in NM between every 2 subsequent waypoints
+func LegDistances(waypoints []Waypoint, distanceModel geodesy.DistanceModel) []float64 {
+	distances := make([]float64, 0, len(waypoints))
+	for i := 1; i < len(waypoints); i++ {
+		distances = append(distances, distanceModel.DistanceInNM(
+			waypoints[i-1].Latitude,
+			waypoints[i-1].Longitude,
+			waypoints[i].Latitude,
+			waypoints[i].Longitude,
+		))
+	}
+	return distances
+}
+
+// ValidateLegDistances checks no leg between subsequent waypoints is empty, a voyage can not sail a leg of 0 NM
+func ValidateLegDistances(distances []float64) error {
+	for i, distance := range distances {
+		if distance <= 0 {
+			return fmt.Errorf("waypoints %d and %d are at the same position", i, i+1)
+		}
+	}
+	return nil
+}
+
+// PlanRoute generates the timestamps of the waypoints given the departure time,
+// the leg distances in NM and the speed in kn every leg is sailed at
+func PlanRoute(waypoints []Waypoint, departure time.Time, distances []float64, speeds []float64) Route {
+	route := make(Route, 0, len(waypoints))
+	date := departure
+	for i, wp := range waypoints {
+		if i > 0 {
+			hours := distances[i-1] / speeds[i-1]
+			date = date.Add(time.Duration(hours * float64(time.Hour)))
+		}
+		route = append(route, RouteData{
+			Date:      date,
+			Longitude: wp.Longitude,
+			Latitude:  wp.Latitude,
+			Port:      wp.Port,
+		})
+	}
+	return route
+}
+
+// SpeedProfile is the speed per leg that minimises fuel for a voyage with a required arrival time
+type SpeedProfile struct {
+	Departure              time.Time
+	RequiredArrival        time.Time
+	Arrival                time.Time
+	FuelType               FuelType
+	DistanceInNM           float64
+	ConsumtionInMetricTons float64
+	ConsumptionInCO2       float64
+	// ConstantSpeed values are the ones of the voyage sailed at the constant speed arriving at RequiredArrival
+	ConstantSpeedInKnot                 float64
+	ConstantSpeedConsumtionInMetricTons float64
+	Legs                                []*PointToPoint
+}
+
+// PlannedVoyage is the consumption of a voyage planned from waypoints and speeds
+type PlannedVoyage struct {
+	Departure    time.Time
+	Arrival      time.Time
+	DistanceInNM float64
+	Route        *RouteConsumption
+}
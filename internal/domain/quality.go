@@ -0,0 +1,22 @@
+package domain
+
+// WarningCode identifies the kind of issue found on the inputs of a route calculation
+type WarningCode string
+
+const (
+	WarningDraughtFarFromTable WarningCode = "draught_far_from_table"
+	WarningSpeedOutOfTable     WarningCode = "speed_out_of_table"
+	WarningWeatherOutOfTable   WarningCode = "weather_out_of_table"
+	WarningMissingWeather      WarningCode = "missing_weather"
+	WarningLongGap             WarningCode = "long_gap"
+	WarningNoTableInEffect     WarningCode = "fuel_table_not_in_effect"
+	WarningOutOfVoyage         WarningCode = "out_of_voyage"
+)
+
+// Warning is an issue that lowers the quality of a route result.
+// Legs are the indexes of the affected legs, empty when the whole route is affected.
+type Warning struct {
+	Code    WarningCode
+	Message string
+	Legs    []int
+}
@@ -1,10 +1,13 @@
 package domain
 
 import (
-	"math"
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kkr2/vessels/internal/geodesy"
 )
 
 // FuelMap is a structure that describes fuelconsumption based on other properties
@@ -15,6 +18,12 @@ type FuelMap struct {
 	Weather    float64   `db:"beaufort"`
 	Speed      float64   `db:"speed"`
 	Consumtion float64   `db:"consumption"`
+	// WindSector is set on rows that only apply to wind from that sector, nil for all directions
+	WindSector *WindSector `db:"wind_sector"`
+	// VersionID is the fuel table version of the row, ValidFrom and ValidTo are the period it is in effect
+	VersionID uuid.UUID  `db:"version_id"`
+	ValidFrom *time.Time `db:"valid_from"`
+	ValidTo   *time.Time `db:"valid_to"`
 }
 
 // Route is a collection of route datapoints
@@ -25,24 +34,112 @@ type RouteData struct {
 	Date      time.Time `json:"date"`
 	Longitude float64   `json:"longitude"`
 	Latitude  float64   `json:"latitude"`
+	Port      *PortCall `json:"port,omitempty"`
+	// Draught is the draught from this data point on, the draught of the route is used when nil
+	Draught *float64 `json:"draught,omitempty"`
+}
+
+// routeWithDraught is the object form of a route carrying a draught for all its data points
+type routeWithDraught struct {
+	Draught *float64    `json:"draught"`
+	Points  []RouteData `json:"points"`
+}
+
+// UnmarshalJSON accepts a route either as an array of data points or as an object
+// {"draught": 9.1, "points": [...]} whose draught applies from the first data point on
+// when the first data point has no draught of its own
+func (route *Route) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		var points []RouteData
+		if err := json.Unmarshal(data, &points); err != nil {
+			return err
+		}
+		*route = points
+		return nil
+	}
+
+	var withDraught routeWithDraught
+	if err := json.Unmarshal(data, &withDraught); err != nil {
+		return err
+	}
+	if len(withDraught.Points) > 0 && withDraught.Points[0].Draught == nil {
+		withDraught.Points[0].Draught = withDraught.Draught
+	}
+	*route = withDraught.Points
+	return nil
+}
+
+// PortCall tags a route datapoint as a call at a port
+type PortCall struct {
+	Name string `json:"name"`
+	EU   bool   `json:"eu"`
+}
+
+// LegState is the operational state of a vessel during a leg
+type LegState string
+
+const (
+	LegSailing     LegState = "sailing"
+	LegManoeuvring LegState = "manoeuvring"
+	LegAnchored    LegState = "anchored"
+	LegBerthed     LegState = "berthed"
+)
+
+// StateThresholds are the avg speeds in kn below which a leg is not considered sailing
+type StateThresholds struct {
+	// IdleSpeedInKnot is the speed below which the vessel is anchored or berthed
+	IdleSpeedInKnot float64
+	// ManoeuvringSpeedInKnot is the speed below which the vessel is manoeuvring
+	ManoeuvringSpeedInKnot float64
 }
 
 // PointToPoint is a structure that holds information regarding 2 subsequent route datapoints
 type PointToPoint struct {
 	Source               RouteData
 	Destination          RouteData
+	State                LegState
+	Draught              float64
 	TimeDiffInMins       float64
+	DistanceInNM         float64
 	AvgSpeedInKnot       float64
 	AvgWeatherInBeaufort float64
-	AvgDailyConsumtion   float64
-	ExactConsumtion      float64
+	// AvgWindSpeedInKnot, AvgWaveHeightInMeters and WindDirection (degrees the wind blows from,
+	// nil when not known) are weighted like the beaufort
+	AvgWindSpeedInKnot    float64
+	AvgWaveHeightInMeters float64
+	WindDirection         *float64
+	AvgDailyConsumtion    float64
+	ExactConsumtion       float64
+	FuelMapRows           []*FuelMap
+	// HeadingInDegrees is the initial course from source to destination
+	HeadingInDegrees float64
+	// WindSector is where the wind comes from relative to the heading, empty when not known
+	WindSector WindSector
+	// WeatherMissing is set when weather of any sample of the leg could not be retrieved
+	WeatherMissing bool
+	// Confidence is 0-1 and drops as the leg inputs get far from the fuel map rows used
+	Confidence float64
 }
 
-// Coverts given data points to pointToPoint data
-func (route *Route) ConvertToP2P() []*PointToPoint {
-	allRoutePoints := []*PointToPoint{}
+// Normalised validates the coordinates of all data points and returns a copy of the route
+// with longitudes in [-180, 180)
+func (route *Route) Normalised() (Route, error) {
+	normalised := make(Route, len(*route))
+	for i, point := range *route {
+		if err := geodesy.ValidateCoordinate(point.Latitude, point.Longitude); err != nil {
+			return nil, fmt.Errorf("datapoint %d: %w", i, err)
+		}
+		point.Longitude = geodesy.NormaliseLongitude(point.Longitude)
+		normalised[i] = point
+	}
+	return normalised, nil
+}
 
-	// TODO: sort based on time
+// Coverts given data points to pointToPoint data, distances are calculated with the given model.
+// Data points are expected to be sorted by date (see Sanitise).
+func (route *Route) ConvertToP2P(distanceModel geodesy.DistanceModel) []*PointToPoint {
+	allRoutePoints := []*PointToPoint{}
 
 	for i := 1; i < len(*route); i++ {
 
@@ -53,56 +150,69 @@ func (route *Route) ConvertToP2P() []*PointToPoint {
 			Destination:    (*route)[i],
 			TimeDiffInMins: timeDiff.Minutes(),
 		}
-		newP2P.calculateAvgSpeed()
+		newP2P.calculateAvgSpeed(distanceModel)
 		allRoutePoints = append(allRoutePoints, newP2P)
 	}
 
 	return allRoutePoints
 }
 
-// Calculates avg speed in kn given 2 locations with respective time
-func (point *PointToPoint) calculateAvgSpeed() {
-	distanceInNM := distanceinN(point.Source.Latitude, point.Source.Longitude, point.Destination.Latitude, point.Source.Longitude)
-
-	point.AvgSpeedInKnot = (distanceInNM * 60.0) / point.TimeDiffInMins
+// AssignDraughts sets the draught of every leg to the last draught given on its source data point
+// or a data point before it, legs before the first given draught get the default draught
+func AssignDraughts(pointToPoints []*PointToPoint, defaultDraught float64) {
+	draught := defaultDraught
+	for _, ptp := range pointToPoints {
+		if ptp.Source.Draught != nil {
+			draught = *ptp.Source.Draught
+		}
+		ptp.Draught = draught
+	}
 }
 
-// Calculates distance in N given locations
-func distanceinN(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
-	radlat1 := float64(math.Pi * lat1 / 180)
-	radlat2 := float64(math.Pi * lat2 / 180)
-
-	theta := float64(lng1 - lng2)
-	radtheta := float64(math.Pi * theta / 180)
-
-	dist := math.Sin(radlat1)*math.Sin(radlat2) + math.Cos(radlat1)*math.Cos(radlat2)*math.Cos(radtheta)
-	if dist > 1 {
-		dist = 1
+// Calculates distance in NM and avg speed in kn given 2 locations with respective time.
+// Legs without duration get a speed of 0, so no NaN or infinite speed reaches the fuel table.
+func (point *PointToPoint) calculateAvgSpeed(distanceModel geodesy.DistanceModel) {
+	point.DistanceInNM = distanceModel.DistanceInNM(
+		point.Source.Latitude,
+		point.Source.Longitude,
+		point.Destination.Latitude,
+		point.Destination.Longitude,
+	)
+
+	point.AvgSpeedInKnot = 0
+	if point.TimeDiffInMins > 0 {
+		point.AvgSpeedInKnot = (point.DistanceInNM * 60.0) / point.TimeDiffInMins
 	}
-
-	dist = math.Acos(dist)
-	dist = dist * 180 / math.Pi
-	dist = dist * 60 * 1.1515
-
-	return dist * 0.8684
+	point.HeadingInDegrees = geodesy.InitialBearing(
+		point.Source.Latitude,
+		point.Source.Longitude,
+		point.Destination.Latitude,
+		point.Destination.Longitude,
+	)
 }
 
-// FIX: This function is supposing that any 2 points have consecutive days
-// Updates object with weather info required
-func (point *PointToPoint) AddWeatherInfo(srcPointBeaufort, destPointBeaufort float64) {
-	if srcPointBeaufort == destPointBeaufort {
-		point.AvgWeatherInBeaufort = srcPointBeaufort
-		return
+// ClassifyState updates object with the operational state based on avg speed.
+// Idle legs starting or ending at a port call are berthed, otherwise anchored.
+func (point *PointToPoint) ClassifyState(thresholds StateThresholds) {
+	switch {
+	case point.AvgSpeedInKnot < thresholds.IdleSpeedInKnot:
+		if point.Source.Port != nil || point.Destination.Port != nil {
+			point.State = LegBerthed
+		} else {
+			point.State = LegAnchored
+		}
+	case point.AvgSpeedInKnot < thresholds.ManoeuvringSpeedInKnot:
+		point.State = LegManoeuvring
+	default:
+		point.State = LegSailing
 	}
-	//TODO: Make this more accurate
-	point.AvgWeatherInBeaufort = (srcPointBeaufort + destPointBeaufort) / 2.0
-
 }
 
-// Updates object with consumption info required
-func (point *PointToPoint) AddConsumtion(avgConsumption float64) {
+// Updates object with consumption info required and the fuel map rows it was derived from
+func (point *PointToPoint) AddConsumtion(avgConsumption float64, fuelMapRows []*FuelMap) {
 	// update avg consumption
 	point.AvgDailyConsumtion = avgConsumption
+	point.FuelMapRows = fuelMapRows
 	// calculate fraction of the day it takes vessel to go from point to point
 	dayFraction := point.TimeDiffInMins / float64(1440)
 
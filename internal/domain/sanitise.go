@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/kkr2/vessels/internal/geodesy"
+)
+
+const (
+	// RemovedDuplicate is the reason of a datapoint removed because of a duplicate timestamp
+	RemovedDuplicate = "duplicate"
+	// RemovedImpossibleSpeed is the reason of a datapoint removed because reaching it implies an impossible speed
+	RemovedImpossibleSpeed = "impossible_speed"
+)
+
+// RemovedPoint is a route datapoint dropped while sanitising a route
+type RemovedPoint struct {
+	Point  RouteData
+	Reason string
+}
+
+// RouteCleaning reports what was changed on a route while sanitising it
+type RouteCleaning struct {
+	// OutOfOrder is the number of datapoints received with a date earlier than the previous one
+	OutOfOrder int
+	Removed    []*RemovedPoint
+}
+
+// Sanitise returns the route sorted by date, with datapoints of the same date merged and
+// GPS jumps that imply a speed above maxSpeedInKnot dropped
+func (route *Route) Sanitise(maxSpeedInKnot float64, distanceModel geodesy.DistanceModel) (Route, *RouteCleaning) {
+	cleaning := &RouteCleaning{Removed: []*RemovedPoint{}}
+
+	sorted := make(Route, len(*route))
+	copy(sorted, *route)
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Date.Before(sorted[i-1].Date) {
+			cleaning.OutOfOrder++
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	// merge datapoints with the same date, the first one is kept but port calls and draughts are not lost
+	unique := make(Route, 0, len(sorted))
+	for _, rd := range sorted {
+		last := len(unique) - 1
+		if last >= 0 && unique[last].Date.Equal(rd.Date) {
+			if unique[last].Port == nil {
+				unique[last].Port = rd.Port
+			}
+			if unique[last].Draught == nil {
+				unique[last].Draught = rd.Draught
+			}
+			cleaning.Removed = append(cleaning.Removed, &RemovedPoint{Point: rd, Reason: RemovedDuplicate})
+			continue
+		}
+		unique = append(unique, rd)
+	}
+
+	speed := func(a, b RouteData) float64 {
+		distance := distanceModel.DistanceInNM(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		return distance / b.Date.Sub(a.Date).Hours()
+	}
+
+	// the first datapoint is a jump if it is the only one that can not be reached at a possible speed.
+	// The draught of a removed datapoint still applies from the next kept one on.
+	var draught *float64
+	if len(unique) > 2 && speed(unique[0], unique[1]) > maxSpeedInKnot && speed(unique[1], unique[2]) <= maxSpeedInKnot {
+		cleaning.Removed = append(cleaning.Removed, &RemovedPoint{Point: unique[0], Reason: RemovedImpossibleSpeed})
+		draught = unique[0].Draught
+		unique = unique[1:]
+	}
+
+	cleaned := make(Route, 0, len(unique))
+	for _, rd := range unique {
+		if len(cleaned) > 0 && speed(cleaned[len(cleaned)-1], rd) > maxSpeedInKnot {
+			cleaning.Removed = append(cleaning.Removed, &RemovedPoint{Point: rd, Reason: RemovedImpossibleSpeed})
+			if rd.Draught != nil {
+				draught = rd.Draught
+			}
+			continue
+		}
+		if rd.Draught == nil {
+			rd.Draught = draught
+		}
+		draught = nil
+		cleaned = append(cleaned, rd)
+	}
+
+	return cleaned, cleaning
+}
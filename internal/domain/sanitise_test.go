@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkr2/vessels/internal/geodesy"
+)
+
+func TestSanitise(t *testing.T) {
+	model, err := geodesy.ModelByName(geodesy.HaversineModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC)
+	draught := func(d float64) *float64 { return &d }
+	// points on the equator are 0.1 degree (6 nm) apart every hour, a jump is 5 degrees away
+	point := func(hour int, longitude float64, d *float64) RouteData {
+		return RouteData{Date: start.Add(time.Duration(hour) * time.Hour), Longitude: longitude, Draught: d}
+	}
+
+	tests := []struct {
+		name           string
+		route          Route
+		wantLongitudes []float64
+		wantDraughts   []*float64
+		wantRemoved    []string
+		wantOutOfOrder int
+	}{
+		{
+			name:           "clean route",
+			route:          Route{point(0, 0, nil), point(1, 0.1, nil), point(2, 0.2, nil)},
+			wantLongitudes: []float64{0, 0.1, 0.2},
+			wantDraughts:   []*float64{nil, nil, nil},
+			wantRemoved:    []string{},
+		},
+		{
+			name:           "jump at the first point",
+			route:          Route{point(0, 5, nil), point(1, 0.1, nil), point(2, 0.2, nil), point(3, 0.3, nil)},
+			wantLongitudes: []float64{0.1, 0.2, 0.3},
+			wantDraughts:   []*float64{nil, nil, nil},
+			wantRemoved:    []string{RemovedImpossibleSpeed},
+		},
+		{
+			name:           "draught of a first point jump is carried forward",
+			route:          Route{point(0, 5, draught(9)), point(1, 0.1, nil), point(2, 0.2, nil)},
+			wantLongitudes: []float64{0.1, 0.2},
+			wantDraughts:   []*float64{draught(9), nil},
+			wantRemoved:    []string{RemovedImpossibleSpeed},
+		},
+		{
+			name:           "jump in the middle carries its draught forward",
+			route:          Route{point(0, 0, nil), point(1, 0.1, nil), point(2, 5, draught(9)), point(3, 0.3, nil)},
+			wantLongitudes: []float64{0, 0.1, 0.3},
+			wantDraughts:   []*float64{nil, nil, draught(9)},
+			wantRemoved:    []string{RemovedImpossibleSpeed},
+		},
+		{
+			name:           "kept point keeps its own draught",
+			route:          Route{point(0, 0, nil), point(1, 0.1, nil), point(2, 5, draught(9)), point(3, 0.3, draught(7))},
+			wantLongitudes: []float64{0, 0.1, 0.3},
+			wantDraughts:   []*float64{nil, nil, draught(7)},
+			wantRemoved:    []string{RemovedImpossibleSpeed},
+		},
+		{
+			name:           "jump at the last point",
+			route:          Route{point(0, 0, nil), point(1, 0.1, nil), point(2, 5, draught(9))},
+			wantLongitudes: []float64{0, 0.1},
+			wantDraughts:   []*float64{nil, nil},
+			wantRemoved:    []string{RemovedImpossibleSpeed},
+		},
+		{
+			name:           "two points drop the second one",
+			route:          Route{point(0, 5, nil), point(1, 0.1, nil)},
+			wantLongitudes: []float64{5},
+			wantDraughts:   []*float64{nil},
+			wantRemoved:    []string{RemovedImpossibleSpeed},
+		},
+		{
+			name:           "duplicate keeps the first point and its missing draught",
+			route:          Route{point(0, 0, nil), point(1, 0.1, nil), point(1, 0.11, draught(8)), point(2, 0.2, nil)},
+			wantLongitudes: []float64{0, 0.1, 0.2},
+			wantDraughts:   []*float64{nil, draught(8), nil},
+			wantRemoved:    []string{RemovedDuplicate},
+		},
+		{
+			name:           "out of order points are sorted",
+			route:          Route{point(0, 0, nil), point(2, 0.2, nil), point(1, 0.1, nil)},
+			wantLongitudes: []float64{0, 0.1, 0.2},
+			wantDraughts:   []*float64{nil, nil, nil},
+			wantRemoved:    []string{},
+			wantOutOfOrder: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleaned, cleaning := tt.route.Sanitise(30, model)
+			if len(cleaned) != len(tt.wantLongitudes) {
+				t.Fatalf("kept %d points, want %d", len(cleaned), len(tt.wantLongitudes))
+			}
+			for i, rd := range cleaned {
+				if rd.Longitude != tt.wantLongitudes[i] {
+					t.Errorf("point %d longitude = %v, want %v", i, rd.Longitude, tt.wantLongitudes[i])
+				}
+				want := tt.wantDraughts[i]
+				if (rd.Draught == nil) != (want == nil) || (want != nil && *rd.Draught != *want) {
+					t.Errorf("point %d draught = %v, want %v", i, rd.Draught, want)
+				}
+			}
+			if len(cleaning.Removed) != len(tt.wantRemoved) {
+				t.Fatalf("removed %d points, want %d", len(cleaning.Removed), len(tt.wantRemoved))
+			}
+			for i, removed := range cleaning.Removed {
+				if removed.Reason != tt.wantRemoved[i] {
+					t.Errorf("removed point %d reason = %v, want %v", i, removed.Reason, tt.wantRemoved[i])
+				}
+			}
+			if cleaning.OutOfOrder != tt.wantOutOfOrder {
+				t.Errorf("OutOfOrder = %v, want %v", cleaning.OutOfOrder, tt.wantOutOfOrder)
+			}
+		})
+	}
+}
+
+func TestSanitiseKeepsInput(t *testing.T) {
+	model, err := geodesy.ModelByName(geodesy.HaversineModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC)
+	route := Route{
+		{Date: start.Add(time.Hour), Longitude: 0.1},
+		{Date: start, Longitude: 0},
+	}
+	route.Sanitise(30, model)
+	if !route[0].Date.Equal(start.Add(time.Hour)) {
+		t.Errorf("Sanitise reordered the input route")
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import "fmt"
+
+// ShipType is the IMO ship type used by the carbon intensity regulations
+type ShipType string
+
+const (
+	ShipBulkCarrier        ShipType = "bulk_carrier"
+	ShipGasCarrier         ShipType = "gas_carrier"
+	ShipTanker             ShipType = "tanker"
+	ShipContainer          ShipType = "container_ship"
+	ShipGeneralCargo       ShipType = "general_cargo_ship"
+	ShipRefrigeratedCargo  ShipType = "refrigerated_cargo_carrier"
+	ShipCombinationCarrier ShipType = "combination_carrier"
+	ShipLNGCarrier         ShipType = "lng_carrier"
+	ShipRoRoCargo          ShipType = "ro_ro_cargo_ship"
+)
+
+// Vessel holds the particulars of a vessel identified by imo.
+// GT, main fuel, design speed (in knots) and consumptions of non sailing states
+// (in metric tons per day) are nil when not known.
+type Vessel struct {
+	IMO                    int       `db:"imo"`
+	Name                   string    `db:"name"`
+	ShipType               ShipType  `db:"ship_type"`
+	DWT                    float64   `db:"dwt"`
+	GT                     *float64  `db:"gt"`
+	MainFuel               *FuelType `db:"main_fuel"`
+	DesignSpeed            *float64  `db:"design_speed"`
+	ManoeuvringConsumption *float64  `db:"manoeuvring_consumption"`
+	AnchoredConsumption    *float64  `db:"anchored_consumption"`
+	BerthedConsumption     *float64  `db:"berthed_consumption"`
+}
+
+// ValidateIMO checks the imo is a 7 digit IMO ship identification number with a valid check digit.
+// The first six digits are multiplied by 7 to 2 and the last digit of the sum is the check digit, e.g. 9074729.
+func ValidateIMO(imo int) error {
+	if imo < 1000000 || imo > 9999999 {
+		return fmt.Errorf("imo %d must have 7 digits", imo)
+	}
+	sum := 0
+	for weight, rest := 2, imo/10; weight <= 7; weight, rest = weight+1, rest/10 {
+		sum += rest % 10 * weight
+	}
+	if sum%10 != imo%10 {
+		return fmt.Errorf("imo %d has an invalid check digit", imo)
+	}
+	return nil
+}
+
+// AuxiliaryConsumption holds the auxiliary engine and boiler consumption in metric tons per day
+// charged to legs where the vessel is not sailing
+type AuxiliaryConsumption struct {
+	Manoeuvring float64
+	Anchored    float64
+	Berthed     float64
+}
+
+// WithVessel returns the consumptions overridden by the ones known for the vessel
+func (ac AuxiliaryConsumption) WithVessel(vessel *Vessel) AuxiliaryConsumption {
+	if vessel.ManoeuvringConsumption != nil {
+		ac.Manoeuvring = *vessel.ManoeuvringConsumption
+	}
+	if vessel.AnchoredConsumption != nil {
+		ac.Anchored = *vessel.AnchoredConsumption
+	}
+	if vessel.BerthedConsumption != nil {
+		ac.Berthed = *vessel.BerthedConsumption
+	}
+	return ac
+}
+
+// ForState returns the daily consumption of the given non sailing state
+func (ac AuxiliaryConsumption) ForState(state LegState) float64 {
+	switch state {
+	case LegManoeuvring:
+		return ac.Manoeuvring
+	case LegAnchored:
+		return ac.Anchored
+	case LegBerthed:
+		return ac.Berthed
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestValidateIMO(t *testing.T) {
+	tests := []struct {
+		name  string
+		imo   int
+		valid bool
+	}{
+		{name: "valid check digit", imo: 9074729, valid: true},
+		{name: "sample vessel", imo: 1234567, valid: true},
+		{name: "invalid check digit", imo: 9074728, valid: false},
+		{name: "6 digits", imo: 907472, valid: false},
+		{name: "8 digits", imo: 90747290, valid: false},
+		{name: "zero", imo: 0, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIMO(tt.imo)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateIMO(%d) = %v, want nil", tt.imo, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("ValidateIMO(%d) = nil, want an error", tt.imo)
+			}
+		})
+	}
+}
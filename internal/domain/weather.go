@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/kkr2/vessels/internal/geodesy"
+)
+
+// knotsInMeterPerSecond converts wind speeds in m/s to knots
+const knotsInMeterPerSecond = 1.943844
+
+// probableWaveHeights are the WMO probable significant wave heights in meters of every beaufort force
+var probableWaveHeights = [...]float64{0, 0.1, 0.2, 0.6, 1, 2, 3, 4, 5.5, 7, 9, 11.5, 14}
+
+// WindSector is where the wind comes from relative to the heading of the vessel
+type WindSector string
+
+const (
+	WindHead      WindSector = "head"
+	WindBeam      WindSector = "beam"
+	WindFollowing WindSector = "following"
+)
+
+// headSectorLimit and followingSectorLimit are the relative wind angles in degrees bounding the beam sector
+const (
+	headSectorLimit      = 45.0
+	followingSectorLimit = 135.0
+)
+
+// RelativeWindAngle returns the angle in degrees (0-180) between the heading and the direction the wind comes from.
+// 0 is wind from straight ahead, 180 wind from straight astern.
+func RelativeWindAngle(heading float64, windDirection float64) float64 {
+	angle := math.Mod(math.Mod(windDirection-heading, 360)+360, 360)
+	if angle > 180 {
+		angle = 360 - angle
+	}
+	return angle
+}
+
+// WindSectorOf returns the sector of a relative wind angle
+func WindSectorOf(relativeAngle float64) WindSector {
+	switch {
+	case relativeAngle < headSectorLimit:
+		return WindHead
+	case relativeAngle > followingSectorLimit:
+		return WindFollowing
+	default:
+		return WindBeam
+	}
+}
+
+// DirectionalFactors scale the weather part of the consumption by wind sector
+type DirectionalFactors struct {
+	Head      float64
+	Beam      float64
+	Following float64
+}
+
+// Factor returns the factor of the sector, 1 when the sector is not known
+func (df DirectionalFactors) Factor(sector WindSector) float64 {
+	switch sector {
+	case WindHead:
+		return df.Head
+	case WindBeam:
+		return df.Beam
+	case WindFollowing:
+		return df.Following
+	}
+	return 1
+}
+
+// Weather is the weather at a position and time
+type Weather struct {
+	Beaufort        float64
+	WindSpeedInKnot float64
+	// WindDirection is the direction the wind blows from in degrees, nil when not known
+	WindDirection      *float64
+	WaveHeightInMeters float64
+}
+
+// WeatherFromBeaufort estimates the weather of a beaufort force, for providers that only know the beaufort.
+// Wind speed follows the beaufort scale (v = 0.836 B^1.5 m/s), wave height the WMO probable height.
+func WeatherFromBeaufort(beaufort float64) *Weather {
+	force := math.Max(0, beaufort)
+	lower := math.Min(math.Floor(force), float64(len(probableWaveHeights)-1))
+	upper := math.Min(lower+1, float64(len(probableWaveHeights)-1))
+	waveHeight := probableWaveHeights[int(lower)]
+	if upper > lower {
+		waveHeight += (probableWaveHeights[int(upper)] - waveHeight) * (force - lower)
+	}
+
+	return &Weather{
+		Beaufort:           beaufort,
+		WindSpeedInKnot:    0.836 * math.Pow(force, 1.5) * knotsInMeterPerSecond,
+		WaveHeightInMeters: waveHeight,
+	}
+}
+
+// WeatherSample is a position and time along a leg the weather is requested for.
+// Minutes is the part of the leg the sample stands for.
+type WeatherSample struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Minutes   float64
+	Weather   *Weather
+	// Missing is set when no weather could be retrieved for the sample
+	Missing bool
+}
+
+// WeatherSamples splits the leg at every interval boundary (e.g. every full hour) and returns a sample
+// in the middle of every part, positioned along the leg. Legs without duration get a single sample at the source.
+// Weather is left to the caller.
+func (point *PointToPoint) WeatherSamples(interval time.Duration) []*WeatherSample {
+	start := point.Source.Date
+	end := point.Destination.Date
+	if !end.After(start) || interval <= 0 {
+		return []*WeatherSample{point.sampleAt(start, 0)}
+	}
+
+	samples := []*WeatherSample{}
+	for from := start; from.Before(end); {
+		to := from.Truncate(interval).Add(interval)
+		if to.After(end) {
+			to = end
+		}
+		samples = append(samples, point.sampleAt(from.Add(to.Sub(from)/2), to.Sub(from).Minutes()))
+		from = to
+	}
+
+	return samples
+}
+
+// sampleAt positions a sample at time t on the great circle of the leg assuming it is sailed at constant speed
+func (point *PointToPoint) sampleAt(t time.Time, minutes float64) *WeatherSample {
+	fraction := 0.0
+	if total := point.Destination.Date.Sub(point.Source.Date); total > 0 {
+		fraction = float64(t.Sub(point.Source.Date)) / float64(total)
+	}
+	latitude, longitude := geodesy.Intermediate(
+		point.Source.Latitude,
+		point.Source.Longitude,
+		point.Destination.Latitude,
+		point.Destination.Longitude,
+		fraction,
+	)
+	return &WeatherSample{
+		Time:      t,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Minutes:   minutes,
+	}
+}
+
+// AddWeatherInfo updates object with avg weather weighted by the part of the leg every sample stands for.
+// Samples with missing weather are left out, when no sample has weather the fallback beaufort is used.
+func (point *PointToPoint) AddWeatherInfo(samples []*WeatherSample, fallbackBeaufort float64) {
+	if len(samples) == 0 {
+		return
+	}
+	known := make([]*WeatherSample, 0, len(samples))
+	totalMinutes := 0.0
+	for _, s := range samples {
+		if s.Missing || s.Weather == nil {
+			point.WeatherMissing = true
+			continue
+		}
+		known = append(known, s)
+		totalMinutes += s.Minutes
+	}
+	if len(known) == 0 {
+		fallback := WeatherFromBeaufort(fallbackBeaufort)
+		point.AvgWeatherInBeaufort = fallback.Beaufort
+		point.AvgWindSpeedInKnot = fallback.WindSpeedInKnot
+		point.AvgWaveHeightInMeters = fallback.WaveHeightInMeters
+		return
+	}
+
+	totalWeight, beaufort, windSpeed, waveHeight := 0.0, 0.0, 0.0, 0.0
+	directionX, directionY, withDirection := 0.0, 0.0, false
+	for _, s := range known {
+		weight := s.Minutes
+		if totalMinutes == 0 {
+			// leg without duration, every sample counts the same
+			weight = 1
+		}
+		totalWeight += weight
+		beaufort += s.Weather.Beaufort * weight
+		windSpeed += s.Weather.WindSpeedInKnot * weight
+		waveHeight += s.Weather.WaveHeightInMeters * weight
+		if s.Weather.WindDirection != nil {
+			// directions are averaged as vectors so 350 and 10 degrees give 0
+			radians := *s.Weather.WindDirection * math.Pi / 180
+			directionX += math.Cos(radians) * weight * math.Max(s.Weather.WindSpeedInKnot, 1)
+			directionY += math.Sin(radians) * weight * math.Max(s.Weather.WindSpeedInKnot, 1)
+			withDirection = true
+		}
+	}
+
+	point.AvgWeatherInBeaufort = beaufort / totalWeight
+	point.AvgWindSpeedInKnot = windSpeed / totalWeight
+	point.AvgWaveHeightInMeters = waveHeight / totalWeight
+	if withDirection {
+		direction := math.Mod(math.Atan2(directionY, directionX)*180/math.Pi+360, 360)
+		point.WindDirection = &direction
+		if point.DistanceInNM > 0 {
+			point.WindSector = WindSectorOf(RelativeWindAngle(point.HeadingInDegrees, direction))
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package emissions
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// ciiReference holds the reference line parameters (MEPC.353(78)) and the rating
+// boundary vectors exp(d1..d4) (MEPC.354(78)) for a ship type and size range
+type ciiReference struct {
+	// minCapacity is the lower DWT limit the parameters apply to
+	minCapacity float64
+	// fixedCapacity replaces DWT in the reference line when set
+	fixedCapacity float64
+	a             float64
+	c             float64
+	boundaries    [4]float64
+}
+
+// ciiReferences holds the parameters per ship type ordered by descending minCapacity
+var ciiReferences = map[domain.ShipType][]ciiReference{
+	domain.ShipBulkCarrier: {
+		{minCapacity: 279000, fixedCapacity: 279000, a: 4745, c: 0.622, boundaries: [4]float64{0.86, 0.94, 1.06, 1.18}},
+		{minCapacity: 0, a: 4745, c: 0.622, boundaries: [4]float64{0.86, 0.94, 1.06, 1.18}},
+	},
+	domain.ShipGasCarrier: {
+		{minCapacity: 65000, a: 14405e7, c: 2.071, boundaries: [4]float64{0.81, 0.91, 1.12, 1.44}},
+		{minCapacity: 0, a: 8104, c: 0.639, boundaries: [4]float64{0.85, 0.95, 1.06, 1.25}},
+	},
+	domain.ShipTanker: {
+		{minCapacity: 0, a: 5247, c: 0.610, boundaries: [4]float64{0.82, 0.93, 1.08, 1.28}},
+	},
+	domain.ShipContainer: {
+		{minCapacity: 0, a: 1984, c: 0.489, boundaries: [4]float64{0.83, 0.94, 1.07, 1.19}},
+	},
+	domain.ShipGeneralCargo: {
+		{minCapacity: 20000, a: 31948, c: 0.792, boundaries: [4]float64{0.83, 0.94, 1.06, 1.19}},
+		{minCapacity: 0, a: 588, c: 0.3885, boundaries: [4]float64{0.83, 0.94, 1.06, 1.19}},
+	},
+	domain.ShipRefrigeratedCargo: {
+		{minCapacity: 0, a: 4600, c: 0.557, boundaries: [4]float64{0.78, 0.91, 1.07, 1.20}},
+	},
+	domain.ShipCombinationCarrier: {
+		{minCapacity: 0, a: 5119, c: 0.622, boundaries: [4]float64{0.87, 0.96, 1.06, 1.14}},
+	},
+	domain.ShipLNGCarrier: {
+		{minCapacity: 100000, a: 9.827, c: 0, boundaries: [4]float64{0.89, 0.98, 1.06, 1.13}},
+		{minCapacity: 65000, a: 14479e10, c: 2.673, boundaries: [4]float64{0.78, 0.92, 1.10, 1.37}},
+		{minCapacity: 0, fixedCapacity: 65000, a: 14779e10, c: 2.673, boundaries: [4]float64{0.78, 0.92, 1.10, 1.37}},
+	},
+	domain.ShipRoRoCargo: {
+		{minCapacity: 0, a: 1967, c: 0.485, boundaries: [4]float64{0.66, 0.90, 1.11, 1.37}},
+	},
+}
+
+// ciiReductionFactors are the reduction factors Z in % relative to the 2019 reference line
+var ciiReductionFactors = map[int]float64{
+	2019: 0,
+	2020: 1,
+	2021: 2,
+	2022: 3,
+	2023: 5,
+	2024: 7,
+	2025: 9,
+	2026: 11,
+	2027: 13.625,
+	2028: 16.25,
+	2029: 18.875,
+	2030: 21.5,
+}
+
+// ciiRatings are the ratings in order of the boundaries, the last one applies above all boundaries
+var ciiRatings = [5]string{"A", "B", "C", "D", "E"}
+
+// CalculateCII calculates the attained AER of a vessel and rates it against the required CII of the year.
+// co2InTonnes and distanceInNM are the totals of the reporting period.
+func CalculateCII(vessel *domain.Vessel, year int, co2InTonnes float64, distanceInNM float64) (*domain.CIIRating, error) {
+	references, exists := ciiReferences[vessel.ShipType]
+	if !exists {
+		return nil, fmt.Errorf("no CII reference line for ship type %q", vessel.ShipType)
+	}
+	reductionFactor, exists := ciiReductionFactors[year]
+	if !exists {
+		return nil, fmt.Errorf("no CII reduction factor for year %d", year)
+	}
+	if vessel.DWT <= 0 {
+		return nil, fmt.Errorf("vessel %d has no deadweight", vessel.IMO)
+	}
+	if distanceInNM <= 0 {
+		return nil, fmt.Errorf("no distance sailed")
+	}
+
+	var ref ciiReference
+	for _, r := range references {
+		if vessel.DWT >= r.minCapacity {
+			ref = r
+			break
+		}
+	}
+	capacity := vessel.DWT
+	if ref.fixedCapacity > 0 {
+		capacity = ref.fixedCapacity
+	}
+
+	// grams of CO2 per capacity tonne and nautical mile
+	attained := co2InTonnes * 1e6 / (vessel.DWT * distanceInNM)
+	reference := ref.a * math.Pow(capacity, -ref.c)
+	required := (1 - reductionFactor/100) * reference
+
+	rating := &domain.CIIRating{
+		IMO:              vessel.IMO,
+		Year:             year,
+		ShipType:         vessel.ShipType,
+		Capacity:         capacity,
+		DistanceInNM:     distanceInNM,
+		ConsumptionInCO2: co2InTonnes,
+		AttainedCII:      attained,
+		ReferenceCII:     reference,
+		ReductionFactor:  reductionFactor,
+		RequiredCII:      required,
+		Rating:           ciiRatings[len(ciiRatings)-1],
+	}
+	for i, d := range ref.boundaries {
+		rating.Boundaries[i] = d * required
+	}
+	for i, boundary := range rating.Boundaries {
+		if attained < boundary {
+			rating.Rating = ciiRatings[i]
+			break
+		}
+	}
+
+	return rating, nil
+}
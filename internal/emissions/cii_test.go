@@ -0,0 +1,95 @@
+package emissions
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+func TestCalculateCIIRating(t *testing.T) {
+	vessel := &domain.Vessel{IMO: 2345674, ShipType: domain.ShipBulkCarrier, DWT: 81000}
+	const distance = 50000.0
+	// required CII of a 81000 DWT bulk carrier in 2023, 5% below the reference line
+	required := 0.95 * 4745 * math.Pow(81000, -0.622)
+	tests := []struct {
+		name string
+		// attained is the attained CII relative to the required one
+		attained   float64
+		wantRating string
+	}{
+		{name: "A", attained: 0.80, wantRating: "A"},
+		{name: "just below the A boundary", attained: 0.859, wantRating: "A"},
+		{name: "just above the A boundary", attained: 0.861, wantRating: "B"},
+		{name: "B", attained: 0.90, wantRating: "B"},
+		{name: "C", attained: 1.00, wantRating: "C"},
+		{name: "D", attained: 1.10, wantRating: "D"},
+		{name: "just below the E boundary", attained: 1.179, wantRating: "D"},
+		{name: "E", attained: 1.25, wantRating: "E"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			co2 := tt.attained * required * vessel.DWT * distance / 1e6
+			rating, err := CalculateCII(vessel, 2023, co2, distance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rating.Rating != tt.wantRating {
+				t.Errorf("Rating = %v, want %v", rating.Rating, tt.wantRating)
+			}
+			if math.Abs(rating.RequiredCII-required) > 1e-9 {
+				t.Errorf("RequiredCII = %v, want %v", rating.RequiredCII, required)
+			}
+			if math.Abs(rating.AttainedCII-tt.attained*required) > 1e-9 {
+				t.Errorf("AttainedCII = %v, want %v", rating.AttainedCII, tt.attained*required)
+			}
+		})
+	}
+}
+
+func TestCalculateCIICapacity(t *testing.T) {
+	tests := []struct {
+		name         string
+		vessel       *domain.Vessel
+		wantCapacity float64
+	}{
+		{name: "deadweight", vessel: &domain.Vessel{ShipType: domain.ShipBulkCarrier, DWT: 81000}, wantCapacity: 81000},
+		{name: "capped bulk carrier", vessel: &domain.Vessel{ShipType: domain.ShipBulkCarrier, DWT: 300000}, wantCapacity: 279000},
+		{name: "small LNG carrier", vessel: &domain.Vessel{ShipType: domain.ShipLNGCarrier, DWT: 40000}, wantCapacity: 65000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rating, err := CalculateCII(tt.vessel, 2024, 1000, 10000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rating.Capacity != tt.wantCapacity {
+				t.Errorf("Capacity = %v, want %v", rating.Capacity, tt.wantCapacity)
+			}
+			if rating.ReductionFactor != 7 {
+				t.Errorf("ReductionFactor = %v, want 7", rating.ReductionFactor)
+			}
+		})
+	}
+}
+
+func TestCalculateCIIErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		vessel   *domain.Vessel
+		year     int
+		distance float64
+	}{
+		{name: "unknown ship type", vessel: &domain.Vessel{ShipType: "yacht", DWT: 1000}, year: 2023, distance: 100},
+		{name: "year without reduction factor", vessel: &domain.Vessel{ShipType: domain.ShipTanker, DWT: 1000}, year: 2031, distance: 100},
+		{name: "no deadweight", vessel: &domain.Vessel{ShipType: domain.ShipTanker}, year: 2023, distance: 100},
+		{name: "no distance", vessel: &domain.Vessel{ShipType: domain.ShipTanker, DWT: 1000}, year: 2023},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CalculateCII(tt.vessel, tt.year, 100, tt.distance); err == nil {
+				t.Error("CalculateCII returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package emissions
+
+import (
+	"fmt"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+const (
+	// etsFirstYear is the first year shipping emissions are in scope of the EU ETS
+	etsFirstYear = 2024
+	// etsIntraEUShare is the share of emissions in scope for voyages between EU ports
+	etsIntraEUShare = 1.0
+	// etsExtraEUShare is the share of emissions in scope for voyages between an EU and a non EU port
+	etsExtraEUShare = 0.5
+)
+
+// etsPhaseIn is the % of in scope emissions that have to be surrendered per year, later years surrender 100%
+var etsPhaseIn = map[int]float64{
+	2024: 40,
+	2025: 70,
+}
+
+// CalculateETS calculates the EU ETS exposure of a route given its legs and the CO2 factor of the fuel burned.
+// Voyages are delimited by the port calls on the route datapoints. Legs before the first or after the last
+// port call are not part of a known voyage, they are left out and returned as OutOfVoyageLegs.
+func CalculateETS(legs []*domain.PointToPoint, co2Factor float64, year int) (*domain.ETSExposure, error) {
+	if year < etsFirstYear {
+		return nil, fmt.Errorf("shipping is in scope of EU ETS from %d", etsFirstYear)
+	}
+	phaseIn, exists := etsPhaseIn[year]
+	if !exists {
+		phaseIn = 100
+	}
+
+	// last port call at or before every leg and first port call at or after it
+	previousPorts := make([]*domain.PortCall, len(legs))
+	nextPorts := make([]*domain.PortCall, len(legs))
+	var port *domain.PortCall
+	for i, ptp := range legs {
+		if ptp.Source.Port != nil {
+			port = ptp.Source.Port
+		}
+		previousPorts[i] = port
+	}
+	port = nil
+	for i := len(legs) - 1; i >= 0; i-- {
+		if legs[i].Destination.Port != nil {
+			port = legs[i].Destination.Port
+		}
+		nextPorts[i] = port
+	}
+
+	exposure := &domain.ETSExposure{Year: year, PhaseIn: phaseIn}
+	for i, ptp := range legs {
+		if previousPorts[i] == nil || nextPorts[i] == nil {
+			exposure.OutOfVoyageLegs = append(exposure.OutOfVoyageLegs, i)
+			continue
+		}
+		co2 := ptp.ExactConsumtion * co2Factor
+		switch {
+		case isEUPort(previousPorts[i]) && isEUPort(nextPorts[i]):
+			exposure.IntraEUCO2 += co2
+			exposure.CO2InScope += co2 * etsIntraEUShare
+		case isEUPort(previousPorts[i]) || isEUPort(nextPorts[i]):
+			exposure.ExtraEUCO2 += co2
+			exposure.CO2InScope += co2 * etsExtraEUShare
+		}
+	}
+	// one allowance covers one tonne of CO2
+	exposure.EUAs = exposure.CO2InScope * phaseIn / 100
+
+	return exposure, nil
+}
+
+func isEUPort(port *domain.PortCall) bool {
+	return port != nil && port.EU
+}
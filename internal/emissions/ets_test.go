@@ -0,0 +1,129 @@
+package emissions
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+var (
+	rotterdam = &domain.PortCall{Name: "Rotterdam", EU: true}
+	hamburg   = &domain.PortCall{Name: "Hamburg", EU: true}
+	newYork   = &domain.PortCall{Name: "New York"}
+	houston   = &domain.PortCall{Name: "Houston"}
+)
+
+// etsLeg returns a leg between the given port calls, nil when the datapoint is not a port call
+func etsLeg(source, destination *domain.PortCall, consumption float64) *domain.PointToPoint {
+	return &domain.PointToPoint{
+		Source:          domain.RouteData{Port: source},
+		Destination:     domain.RouteData{Port: destination},
+		ExactConsumtion: consumption,
+	}
+}
+
+func TestCalculateETSPhaseIn(t *testing.T) {
+	legs := []*domain.PointToPoint{etsLeg(rotterdam, hamburg, 10)}
+	tests := []struct {
+		year        int
+		wantPhaseIn float64
+		wantErr     bool
+	}{
+		{year: 2023, wantErr: true},
+		{year: 2024, wantPhaseIn: 40},
+		{year: 2025, wantPhaseIn: 70},
+		{year: 2026, wantPhaseIn: 100},
+		{year: 2030, wantPhaseIn: 100},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.year), func(t *testing.T) {
+			exposure, err := CalculateETS(legs, 1, tt.year)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("CalculateETS returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exposure.PhaseIn != tt.wantPhaseIn {
+				t.Errorf("PhaseIn = %v, want %v", exposure.PhaseIn, tt.wantPhaseIn)
+			}
+			if want := 10 * tt.wantPhaseIn / 100; math.Abs(exposure.EUAs-want) > 1e-9 {
+				t.Errorf("EUAs = %v, want %v", exposure.EUAs, want)
+			}
+		})
+	}
+}
+
+func TestCalculateETSScope(t *testing.T) {
+	tests := []struct {
+		name                string
+		legs                []*domain.PointToPoint
+		wantIntraEU         float64
+		wantExtraEU         float64
+		wantInScope         float64
+		wantOutOfVoyageLegs []int
+	}{
+		{
+			name:        "intra EU voyage",
+			legs:        []*domain.PointToPoint{etsLeg(rotterdam, nil, 10), etsLeg(nil, hamburg, 10)},
+			wantIntraEU: 20, wantInScope: 20,
+		},
+		{
+			name:        "extra EU voyage is half in scope",
+			legs:        []*domain.PointToPoint{etsLeg(rotterdam, nil, 10), etsLeg(nil, newYork, 10)},
+			wantExtraEU: 20, wantInScope: 10,
+		},
+		{
+			name: "non EU voyage",
+			legs: []*domain.PointToPoint{etsLeg(newYork, houston, 10)},
+		},
+		{
+			name:        "at berth in an EU port",
+			legs:        []*domain.PointToPoint{etsLeg(hamburg, hamburg, 2)},
+			wantIntraEU: 2, wantInScope: 2,
+		},
+		{
+			name: "legs before the first and after the last port call are left out",
+			legs: []*domain.PointToPoint{
+				etsLeg(nil, rotterdam, 10),
+				etsLeg(rotterdam, nil, 10),
+				etsLeg(nil, hamburg, 10),
+				etsLeg(hamburg, nil, 10),
+				etsLeg(nil, newYork, 10),
+				etsLeg(newYork, nil, 10),
+			},
+			wantIntraEU: 20, wantExtraEU: 20, wantInScope: 30,
+			wantOutOfVoyageLegs: []int{0, 5},
+		},
+		{
+			name:                "route without port calls",
+			legs:                []*domain.PointToPoint{etsLeg(nil, nil, 10), etsLeg(nil, nil, 10)},
+			wantOutOfVoyageLegs: []int{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exposure, err := CalculateETS(tt.legs, 1, 2026)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(exposure.IntraEUCO2-tt.wantIntraEU) > 1e-9 {
+				t.Errorf("IntraEUCO2 = %v, want %v", exposure.IntraEUCO2, tt.wantIntraEU)
+			}
+			if math.Abs(exposure.ExtraEUCO2-tt.wantExtraEU) > 1e-9 {
+				t.Errorf("ExtraEUCO2 = %v, want %v", exposure.ExtraEUCO2, tt.wantExtraEU)
+			}
+			if math.Abs(exposure.CO2InScope-tt.wantInScope) > 1e-9 {
+				t.Errorf("CO2InScope = %v, want %v", exposure.CO2InScope, tt.wantInScope)
+			}
+			if fmt.Sprint(exposure.OutOfVoyageLegs) != fmt.Sprint(tt.wantOutOfVoyageLegs) {
+				t.Errorf("OutOfVoyageLegs = %v, want %v", exposure.OutOfVoyageLegs, tt.wantOutOfVoyageLegs)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package emissions
+
+import (
+	"fmt"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+const (
+	// fuelEUReferenceIntensity is the 2020 fleet average GHG intensity in gCO2e/MJ
+	fuelEUReferenceIntensity = 91.16
+	// fuelEUFirstYear is the first reporting year of FuelEU Maritime
+	fuelEUFirstYear = 2025
+	// fuelEUPenaltyPerTonneVLSFO is the penalty in EUR per tonne of VLSFO equivalent energy not compliant
+	fuelEUPenaltyPerTonneVLSFO = 2400
+	// vlsfoEnergyPerTonne is the energy of a tonne of VLSFO in MJ
+	vlsfoEnergyPerTonne = 41000
+
+	// global warming potentials over 100 years
+	gwpCH4 = 25
+	gwpN2O = 298
+)
+
+// fuelEUReductions are the reductions in % of the reference intensity starting from the given year
+var fuelEUReductions = []struct {
+	fromYear  int
+	reduction float64
+}{
+	{2050, 80},
+	{2045, 62},
+	{2040, 31},
+	{2035, 14.5},
+	{2030, 6},
+	{2025, 2},
+}
+
+// FuelEUTarget returns the GHG intensity target in gCO2e/MJ of the reporting year
+func FuelEUTarget(year int) (float64, error) {
+	for _, r := range fuelEUReductions {
+		if year >= r.fromYear {
+			return fuelEUReferenceIntensity * (1 - r.reduction/100), nil
+		}
+	}
+	return 0, fmt.Errorf("FuelEU Maritime applies from %d", fuelEUFirstYear)
+}
+
+// CalculateFuelEU calculates the well to wake GHG intensity of the fuels burned and the compliance
+// balance against the target of the year. Fuel consumptions are in metric tons.
+func (r *Registry) CalculateFuelEU(year int, fuels []*domain.FuelConsumption) (*domain.FuelEUBalance, error) {
+	target, err := FuelEUTarget(year)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &domain.FuelEUBalance{Year: year, TargetIntensity: target, Fuels: fuels}
+	wtt, ttw := 0.0, 0.0
+	for _, fc := range fuels {
+		factors, err := r.GHGFactors(fc.FuelType)
+		if err != nil {
+			return nil, err
+		}
+		grams := fc.ConsumtionInMetricTons * 1e6
+		energy := grams * factors.LCV
+		burnt := 1 - factors.Slip/100
+
+		balance.EnergyInMJ += energy
+		wtt += energy * (factors.WtTCO2 + factors.WtTCH4*gwpCH4 + factors.WtTN2O*gwpN2O)
+		ttw += grams * (burnt*(factors.TtWCO2+factors.TtWCH4*gwpCH4+factors.TtWN2O*gwpN2O) +
+			// slipped fuel is emitted as methane
+			factors.Slip/100*gwpCH4)
+	}
+	if balance.EnergyInMJ <= 0 {
+		return nil, fmt.Errorf("no energy consumed")
+	}
+
+	balance.WtTIntensity = wtt / balance.EnergyInMJ
+	balance.TtWIntensity = ttw / balance.EnergyInMJ
+	balance.GHGIntensity = balance.WtTIntensity + balance.TtWIntensity
+	// positive balance is a surplus, negative a deficit
+	balance.ComplianceBalance = (target - balance.GHGIntensity) * balance.EnergyInMJ
+	if balance.ComplianceBalance < 0 {
+		balance.Penalty = -balance.ComplianceBalance / (balance.GHGIntensity * vlsfoEnergyPerTonne) * fuelEUPenaltyPerTonneVLSFO
+	}
+
+	return balance, nil
+}
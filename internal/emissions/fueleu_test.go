@@ -0,0 +1,107 @@
+package emissions
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+func TestFuelEUTarget(t *testing.T) {
+	tests := []struct {
+		year    int
+		want    float64
+		wantErr bool
+	}{
+		{year: 2024, wantErr: true},
+		{year: 2025, want: 91.16 * 0.98},
+		{year: 2029, want: 91.16 * 0.98},
+		{year: 2030, want: 91.16 * 0.94},
+		{year: 2035, want: 91.16 * 0.855},
+		{year: 2050, want: 91.16 * 0.2},
+		{year: 2060, want: 91.16 * 0.2},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.year), func(t *testing.T) {
+			got, err := FuelEUTarget(tt.year)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("FuelEUTarget returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("FuelEUTarget(%d) = %v, want %v", tt.year, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateFuelEU(t *testing.T) {
+	registry := NewRegistry(&config.Config{})
+	tests := []struct {
+		name                  string
+		fuels                 []*domain.FuelConsumption
+		wantIntensity         float64
+		wantComplianceBalance float64
+		wantPenalty           float64
+	}{
+		{
+			// 100 t of HFO is 4.05e6 MJ at 13.5 + (3.114 + 0.00005*25 + 0.00018*298) / 0.0405 gCO2e/MJ.
+			// The deficit is converted to tonnes of VLSFO (41000 MJ/t) at the attained intensity and charged 2400 EUR/t
+			name:                  "deficit is charged the penalty",
+			fuels:                 []*domain.FuelConsumption{{FuelType: domain.FuelHFO, ConsumtionInMetricTons: 100}},
+			wantIntensity:         91.7441975308642,
+			wantComplianceBalance: -9749960,
+			wantPenalty:           9749960 / (91.7441975308642 * 41000) * 2400,
+		},
+		{
+			name:                  "surplus is not charged",
+			fuels:                 []*domain.FuelConsumption{{FuelType: domain.FuelLNG, ConsumtionInMetricTons: 100}},
+			wantIntensity:         89.20292912423625,
+			wantComplianceBalance: (91.16*0.98 - 89.20292912423625) * 100e6 * 0.0491,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance, err := registry.CalculateFuelEU(2025, tt.fuels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(balance.GHGIntensity-tt.wantIntensity) > 1e-6 {
+				t.Errorf("GHGIntensity = %v, want %v", balance.GHGIntensity, tt.wantIntensity)
+			}
+			if math.Abs(balance.ComplianceBalance-tt.wantComplianceBalance) > 1e-3 {
+				t.Errorf("ComplianceBalance = %v, want %v", balance.ComplianceBalance, tt.wantComplianceBalance)
+			}
+			if math.Abs(balance.Penalty-tt.wantPenalty) > 1e-6 {
+				t.Errorf("Penalty = %v, want %v", balance.Penalty, tt.wantPenalty)
+			}
+		})
+	}
+}
+
+func TestCalculateFuelEUErrors(t *testing.T) {
+	registry := NewRegistry(&config.Config{})
+	tests := []struct {
+		name  string
+		year  int
+		fuels []*domain.FuelConsumption
+	}{
+		{name: "before FuelEU", year: 2024, fuels: []*domain.FuelConsumption{{FuelType: domain.FuelHFO, ConsumtionInMetricTons: 1}}},
+		{name: "unknown fuel", year: 2025, fuels: []*domain.FuelConsumption{{FuelType: "COAL", ConsumtionInMetricTons: 1}}},
+		{name: "no energy", year: 2025, fuels: []*domain.FuelConsumption{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := registry.CalculateFuelEU(tt.year, tt.fuels); err == nil {
+				t.Error("CalculateFuelEU returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package emissions
+
+import (
+	"fmt"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// defaultCO2Factors are the IMO carbon factors (Cf) in tonnes of CO2 per tonne of fuel
+var defaultCO2Factors = map[domain.FuelType]float64{
+	domain.FuelHFO:      3.114,
+	domain.FuelLFO:      3.151,
+	domain.FuelLSFO:     3.151,
+	domain.FuelMGO:      3.206,
+	domain.FuelLNG:      2.750,
+	domain.FuelMethanol: 1.375,
+}
+
+// defaultGHGFactors are the FuelEU Maritime default well to wake factors (Annex II)
+var defaultGHGFactors = map[domain.FuelType]domain.GHGFactors{
+	domain.FuelHFO:      {LCV: 0.0405, WtTCO2: 13.5, TtWCO2: 3.114, TtWCH4: 0.00005, TtWN2O: 0.00018},
+	domain.FuelLFO:      {LCV: 0.0410, WtTCO2: 13.2, TtWCO2: 3.151, TtWCH4: 0.00005, TtWN2O: 0.00018},
+	domain.FuelLSFO:     {LCV: 0.0410, WtTCO2: 13.2, TtWCO2: 3.151, TtWCH4: 0.00005, TtWN2O: 0.00018},
+	domain.FuelMGO:      {LCV: 0.0427, WtTCO2: 14.4, TtWCO2: 3.206, TtWCH4: 0.00005, TtWN2O: 0.00018},
+	domain.FuelLNG:      {LCV: 0.0491, WtTCO2: 18.5, TtWCO2: 2.750, TtWCH4: 0, TtWN2O: 0.00011, Slip: 3.1},
+	domain.FuelMethanol: {LCV: 0.0199, WtTCO2: 31.3, TtWCO2: 1.375},
+}
+
+// Registry holds emission factors keyed by fuel type
+type Registry struct {
+	co2Factors  map[domain.FuelType]float64
+	ghgFactors  map[domain.FuelType]domain.GHGFactors
+	defaultFuel domain.FuelType
+}
+
+// NewRegistry creates a registry with the IMO defaults, overridden and extended by config
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{
+		co2Factors:  make(map[domain.FuelType]float64, len(defaultCO2Factors)),
+		ghgFactors:  make(map[domain.FuelType]domain.GHGFactors, len(defaultGHGFactors)),
+		defaultFuel: domain.FuelHFO,
+	}
+	for fuel, factor := range defaultCO2Factors {
+		r.co2Factors[fuel] = factor
+	}
+	for fuel, factors := range defaultGHGFactors {
+		r.ghgFactors[fuel] = factors
+	}
+	// viper lowercases map keys so fuel types are normalised
+	for fuel, factor := range cfg.Calculation.EmissionFactors {
+		r.co2Factors[domain.NormaliseFuelType(fuel)] = factor
+	}
+	for fuel, factors := range cfg.Calculation.GHGFactors {
+		r.ghgFactors[domain.NormaliseFuelType(fuel)] = domain.GHGFactors(factors)
+	}
+	if cfg.Calculation.DefaultFuelType != "" {
+		r.defaultFuel = domain.NormaliseFuelType(cfg.Calculation.DefaultFuelType)
+	}
+
+	return r
+}
+
+// ResolveFuelType returns the normalised fuel type, the configured default is used when empty
+func (r *Registry) ResolveFuelType(fuel string) (domain.FuelType, error) {
+	fuelType := r.defaultFuel
+	if fuel != "" {
+		fuelType = domain.NormaliseFuelType(fuel)
+	}
+	if _, exists := r.co2Factors[fuelType]; !exists {
+		return "", fmt.Errorf("no emission factor for fuel type %q", fuelType)
+	}
+	return fuelType, nil
+}
+
+// CO2Factor returns tonnes of CO2 emitted per tonne of the given fuel
+func (r *Registry) CO2Factor(fuel domain.FuelType) (float64, error) {
+	factor, exists := r.co2Factors[fuel]
+	if !exists {
+		return 0, fmt.Errorf("no emission factor for fuel type %q", fuel)
+	}
+	return factor, nil
+}
+
+// GHGFactors returns the FuelEU well to wake factors of the given fuel
+func (r *Registry) GHGFactors(fuel domain.FuelType) (domain.GHGFactors, error) {
+	factors, exists := r.ghgFactors[fuel]
+	if !exists {
+		return domain.GHGFactors{}, fmt.Errorf("no GHG factors for fuel type %q", fuel)
+	}
+	return factors, nil
+}
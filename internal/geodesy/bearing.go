@@ -0,0 +1,17 @@
+package geodesy
+
+import "math"
+
+// InitialBearing returns the great circle course in degrees (0-360, clockwise from north)
+// when leaving the first coordinate towards the second one
+func InitialBearing(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dLambda := toRadians(lng2 - lng1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	theta := math.Atan2(y, x) * 180 / math.Pi
+
+	return math.Mod(theta+360, 360)
+}
@@ -0,0 +1,50 @@
+package geodesy
+
+import (
+	"fmt"
+	"math"
+)
+
+// NormaliseLongitude brings a longitude in degrees into [-180, 180), so 190 becomes -170
+func NormaliseLongitude(lng float64) float64 {
+	return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+}
+
+// ValidateCoordinate checks that latitude is within [-90, 90] and longitude within [-180, 360],
+// so both the -180/180 and the 0/360 longitude conventions are accepted
+func ValidateCoordinate(lat, lng float64) error {
+	if math.IsNaN(lat) || math.IsInf(lat, 0) || math.IsNaN(lng) || math.IsInf(lng, 0) {
+		return fmt.Errorf("coordinate %v,%v is not a number", lat, lng)
+	}
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude %v is out of range [-90, 90]", lat)
+	}
+	if lng < -180 || lng > 360 {
+		return fmt.Errorf("longitude %v is out of range [-180, 360]", lng)
+	}
+	return nil
+}
+
+// Intermediate returns the point at the given fraction (0-1) of the great circle from the first coordinate
+// to the second one. It is safe across the anti-meridian and over the poles, the returned longitude is normalised.
+func Intermediate(lat1, lng1, lat2, lng2, fraction float64) (float64, float64) {
+	phi1, lambda1 := toRadians(lat1), toRadians(lng1)
+	phi2, lambda2 := toRadians(lat2), toRadians(lng2)
+
+	delta := haversine{}.DistanceInNM(lat1, lng1, lat2, lng2) * MetersInNM / earthMeanRadius
+	if math.Sin(delta) < 1e-12 {
+		// coincident or antipodal points, there is no single great circle so move along the parallel
+		lng := lng1 + NormaliseLongitude(lng2-lng1)*fraction
+		return lat1 + (lat2-lat1)*fraction, NormaliseLongitude(lng)
+	}
+
+	a := math.Sin((1-fraction)*delta) / math.Sin(delta)
+	b := math.Sin(fraction*delta) / math.Sin(delta)
+	x := a*math.Cos(phi1)*math.Cos(lambda1) + b*math.Cos(phi2)*math.Cos(lambda2)
+	y := a*math.Cos(phi1)*math.Sin(lambda1) + b*math.Cos(phi2)*math.Sin(lambda2)
+	z := a*math.Sin(phi1) + b*math.Sin(phi2)
+
+	lat := math.Atan2(z, math.Sqrt(x*x+y*y)) * 180 / math.Pi
+	lng := math.Atan2(y, x) * 180 / math.Pi
+	return lat, NormaliseLongitude(lng)
+}
@@ -0,0 +1,69 @@
+package geodesy
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func TestNormaliseLongitude(t *testing.T) {
+	tests := []struct {
+		name string
+		lng  float64
+		want float64
+	}{
+		{name: "within range", lng: 12.5, want: 12.5},
+		{name: "0/360 convention", lng: 190, want: -170},
+		{name: "180 is -180", lng: 180, want: -180},
+		{name: "full turn", lng: 360, want: 0},
+		{name: "179 to -179 is 2 degrees east", lng: -179 - 179, want: 2},
+		{name: "-179 to 179 is 2 degrees west", lng: 179 - -179, want: -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormaliseLongitude(tt.lng); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("NormaliseLongitude(%v) = %v, want %v", tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntermediate(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		fraction               float64
+		wantLat, wantLng       float64
+	}{
+		{name: "start", lat1: 10, lng1: 20, lat2: 30, lng2: 40, fraction: 0, wantLat: 10, wantLng: 20},
+		{name: "end", lat1: 10, lng1: 20, lat2: 30, lng2: 40, fraction: 1, wantLat: 30, wantLng: 40},
+		{name: "equator midpoint", lat1: 0, lng1: 10, lat2: 0, lng2: 30, fraction: 0.5, wantLat: 0, wantLng: 20},
+		{name: "across the anti-meridian", lat1: 0, lng1: 179, lat2: 0, lng2: -179, fraction: 0.25, wantLat: 0, wantLng: 179.5},
+		{name: "over the pole", lat1: 80, lng1: 0, lat2: 80, lng2: 180, fraction: 0.5, wantLat: 90},
+		{name: "coincident", lat1: 45, lng1: -30, lat2: 45, lng2: -30, fraction: 0.5, wantLat: 45, wantLng: -30},
+		{name: "antipodal moves along the parallel", lat1: 0, lng1: 0, lat2: 0, lng2: 180, fraction: 0.5, wantLat: 0, wantLng: -90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lng := Intermediate(tt.lat1, tt.lng1, tt.lat2, tt.lng2, tt.fraction)
+			if math.Abs(lat-tt.wantLat) > 1e-6 {
+				t.Errorf("latitude = %v, want %v", lat, tt.wantLat)
+			}
+			// the longitude of a pole is undefined
+			if math.Abs(tt.wantLat) < 90 && math.Abs(lng-tt.wantLng) > 1e-6 {
+				t.Errorf("longitude = %v, want %v", lng, tt.wantLng)
+			}
+		})
+	}
+}
+
+func TestAntiMeridianDistance(t *testing.T) {
+	// 2 degrees of longitude on the equator, not the 358 degrees the other way round
+	want := 2 * 60.0
+	for _, model := range []DistanceModel{haversine{}, vincenty{}} {
+		if got := model.DistanceInNM(0, 179, 0, -179); math.Abs(got-want) > 1 {
+			t.Errorf("%s distance from 179 to -179 = %v NM, want about %v NM", model.Name(), got, want)
+		}
+	}
+}
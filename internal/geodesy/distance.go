@@ -0,0 +1,41 @@
+package geodesy
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// MetersInNM is the length of an international nautical mile
+	MetersInNM = 1852.0
+
+	// HaversineModel is the name of the spherical distance model
+	HaversineModel = "haversine"
+	// VincentyModel is the name of the WGS84 ellipsoidal distance model
+	VincentyModel = "vincenty"
+)
+
+// DistanceModel calculates distance between two coordinates given in degrees
+type DistanceModel interface {
+	Name() string
+	DistanceInNM(lat1, lng1, lat2, lng2 float64) float64
+}
+
+// models holds all available distance models by name
+var models = map[string]DistanceModel{
+	HaversineModel: haversine{},
+	VincentyModel:  vincenty{},
+}
+
+// ModelByName returns the distance model registered with the given name
+func ModelByName(name string) (DistanceModel, error) {
+	model, exists := models[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown distance model %q", name)
+	}
+	return model, nil
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
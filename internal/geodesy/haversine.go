@@ -0,0 +1,29 @@
+package geodesy
+
+import "math"
+
+// earthMeanRadius is the IUGG mean radius of the earth in meters
+const earthMeanRadius = 6371008.8
+
+// haversine calculates great circle distance on a spherical earth
+type haversine struct{}
+
+func (haversine) Name() string {
+	return HaversineModel
+}
+
+// DistanceInNM returns great circle distance between two coordinates in nautical miles
+func (haversine) DistanceInNM(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dPhi := toRadians(lat2 - lat1)
+	dLambda := toRadians(NormaliseLongitude(lng2 - lng1))
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	// guard against rounding errors pushing a out of [0,1]
+	a = math.Min(1, math.Max(0, a))
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return earthMeanRadius * c / MetersInNM
+}
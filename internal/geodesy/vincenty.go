@@ -0,0 +1,73 @@
+package geodesy
+
+import "math"
+
+// WGS84 ellipsoid parameters
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	wgs84B = wgs84A * (1 - wgs84F)
+
+	vincentyMaxIterations = 200
+	vincentyTolerance     = 1e-12
+)
+
+// vincenty calculates geodesic distance on the WGS84 ellipsoid using Vincenty's inverse formula.
+// For nearly antipodal points where the formula does not converge haversine is used instead.
+type vincenty struct{}
+
+func (vincenty) Name() string {
+	return VincentyModel
+}
+
+// DistanceInNM returns geodesic distance between two coordinates in nautical miles
+func (vincenty) DistanceInNM(lat1, lng1, lat2, lng2 float64) float64 {
+	// difference across the anti-meridian is the short way round, e.g. 179 to -179 is 2 degrees
+	L := toRadians(NormaliseLongitude(lng2 - lng1))
+	U1 := math.Atan((1 - wgs84F) * math.Tan(toRadians(lat1)))
+	U2 := math.Atan((1 - wgs84F) * math.Tan(toRadians(lat2)))
+	sinU1, cosU1 := math.Sin(U1), math.Cos(U1)
+	sinU2, cosU2 := math.Sin(U2), math.Cos(U2)
+
+	lambda := L
+	var sinSigma, cosSigma, sigma, cosSqAlpha, cos2SigmaM float64
+	converged := false
+	for i := 0; i < vincentyMaxIterations; i++ {
+		sinLambda, cosLambda := math.Sin(lambda), math.Cos(lambda)
+		sinSigma = math.Sqrt((cosU2*sinLambda)*(cosU2*sinLambda) +
+			(cosU1*sinU2-sinU1*cosU2*cosLambda)*(cosU1*sinU2-sinU1*cosU2*cosLambda))
+		if sinSigma == 0 {
+			// coincident points
+			return 0
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha = 1 - sinAlpha*sinAlpha
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		} else {
+			// both points on the equator
+			cos2SigmaM = 0
+		}
+		C := wgs84F / 16 * cosSqAlpha * (4 + wgs84F*(4-3*cosSqAlpha))
+		lambdaPrev := lambda
+		lambda = L + (1-C)*wgs84F*sinAlpha*
+			(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-lambdaPrev) < vincentyTolerance {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return haversine{}.DistanceInNM(lat1, lng1, lat2, lng2)
+	}
+
+	uSq := cosSqAlpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+
+	return wgs84B * A * (sigma - deltaSigma) / MetersInNM
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/logger"
+)
+
+// RegistryRepo is the repository interface for vessel particulars
+type RegistryRepo interface {
+	GetVessel(ctx context.Context, imo int) (*domain.Vessel, error)
+	GetVessels(ctx context.Context, imos []int) (map[int]*domain.Vessel, error)
+	ListVessels(ctx context.Context) ([]*domain.Vessel, error)
+	UpsertVessel(ctx context.Context, vessel *domain.Vessel) (*domain.Vessel, error)
+	DeleteVessel(ctx context.Context, imo int) (int64, error)
+}
+
+// registryRepo is a concrete implementation of RegistryRepo
+type registryRepo struct {
+	db  *sqlx.DB
+	log logger.Logger
+}
+
+// NewRegistryRepository is the registry repository constructor
+func NewRegistryRepository(db *sqlx.DB, log logger.Logger) RegistryRepo {
+	return &registryRepo{db: db, log: log}
+}
+
+// GetVessel returns the particulars of the vessel with given imo
+func (rr *registryRepo) GetVessel(ctx context.Context, imo int) (*domain.Vessel, error) {
+	operation := errors.Op("db.registryRepository.GetVessel")
+
+	vessel := &domain.Vessel{}
+	if err := rr.db.QueryRowxContext(ctx, getVessel, imo).StructScan(vessel); err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.E(operation, errors.KindNotFound, "vessel not registered")
+		}
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return vessel, nil
+}
+
+// GetVessels returns the particulars of the registered vessels among imos, keyed by imo
+func (rr *registryRepo) GetVessels(ctx context.Context, imos []int) (map[int]*domain.Vessel, error) {
+	operation := errors.Op("db.registryRepository.GetVessels")
+
+	rows, err := rr.db.QueryxContext(ctx, getVessels, intArray(imos))
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+	defer rows.Close()
+
+	vessels := make(map[int]*domain.Vessel, len(imos))
+	for rows.Next() {
+		vessel := &domain.Vessel{}
+		if err = rows.StructScan(vessel); err != nil {
+			return nil, errors.E(operation, errors.KindInternal, err)
+		}
+		vessels[vessel.IMO] = vessel
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return vessels, nil
+}
+
+// ListVessels returns the particulars of all registered vessels sorted by imo
+func (rr *registryRepo) ListVessels(ctx context.Context) ([]*domain.Vessel, error) {
+	operation := errors.Op("db.registryRepository.ListVessels")
+
+	rows, err := rr.db.QueryxContext(ctx, listVessels)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+	defer rows.Close()
+
+	vessels := []*domain.Vessel{}
+	for rows.Next() {
+		vessel := &domain.Vessel{}
+		if err = rows.StructScan(vessel); err != nil {
+			return nil, errors.E(operation, errors.KindInternal, err)
+		}
+		vessels = append(vessels, vessel)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return vessels, nil
+}
+
+// UpsertVessel creates the vessel or updates its particulars if it already exists
+func (rr *registryRepo) UpsertVessel(ctx context.Context, vessel *domain.Vessel) (*domain.Vessel, error) {
+	operation := errors.Op("db.registryRepository.UpsertVessel")
+
+	saved := &domain.Vessel{}
+	if err := rr.db.QueryRowxContext(
+		ctx, upsertVessel,
+		vessel.IMO,
+		vessel.Name,
+		vessel.ShipType,
+		vessel.DWT,
+		vessel.GT,
+		vessel.MainFuel,
+		vessel.DesignSpeed,
+		vessel.ManoeuvringConsumption,
+		vessel.AnchoredConsumption,
+		vessel.BerthedConsumption,
+	).StructScan(saved); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return saved, nil
+}
+
+// DeleteVessel deletes the particulars of the vessel and returns the number of vessels deleted
+func (rr *registryRepo) DeleteVessel(ctx context.Context, imo int) (int64, error) {
+	operation := errors.Op("db.registryRepository.DeleteVessel")
+
+	res, err := rr.db.ExecContext(ctx, deleteVessel, imo)
+	if err != nil {
+		return 0, errors.E(operation, errors.KindInternal, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.E(operation, errors.KindInternal, err)
+	}
+	return deleted, nil
+}
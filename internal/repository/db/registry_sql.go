@@ -0,0 +1,29 @@
+package db
+
+const (
+	getVessel = `SELECT imo, name, ship_type, dwt, gt, main_fuel, design_speed,
+					manoeuvring_consumption, anchored_consumption, berthed_consumption
+					FROM vessels WHERE imo = $1`
+
+	getVessels = `SELECT imo, name, ship_type, dwt, gt, main_fuel, design_speed,
+					manoeuvring_consumption, anchored_consumption, berthed_consumption
+					FROM vessels WHERE imo = ANY($1::int[])`
+
+	listVessels = `SELECT imo, name, ship_type, dwt, gt, main_fuel, design_speed,
+					manoeuvring_consumption, anchored_consumption, berthed_consumption
+					FROM vessels ORDER BY imo`
+
+	upsertVessel = `INSERT INTO vessels (imo, name, ship_type, dwt, gt, main_fuel, design_speed,
+							manoeuvring_consumption, anchored_consumption, berthed_consumption)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+						ON CONFLICT (imo) DO UPDATE
+						SET name = EXCLUDED.name, ship_type = EXCLUDED.ship_type, dwt = EXCLUDED.dwt,
+							gt = EXCLUDED.gt, main_fuel = EXCLUDED.main_fuel, design_speed = EXCLUDED.design_speed,
+							manoeuvring_consumption = EXCLUDED.manoeuvring_consumption,
+							anchored_consumption = EXCLUDED.anchored_consumption,
+							berthed_consumption = EXCLUDED.berthed_consumption
+						RETURNING imo, name, ship_type, dwt, gt, main_fuel, design_speed,
+							manoeuvring_consumption, anchored_consumption, berthed_consumption`
+
+	deleteVessel = `DELETE FROM vessels WHERE imo = $1`
+)
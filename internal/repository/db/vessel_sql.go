@@ -1,19 +1,76 @@
 package db
 
-
 const (
-	findClosestFuelConsumtion = ` SELECT * FROM fuel f
-									WHERE f.imo = $1 
-									ORDER BY ABS(draught - $2) , ABS(beaufort  - $3) , ABS(speed  - $4)
-									LIMIT 1`
-
-	allFuelMapsWithClosestDr = ` select *
-								from fuel f 
-								where f.imo = $1 and draught = (
-									SELECT f.draught 
-									FROM fuel f
-									WHERE f.imo = $1 
-									ORDER BY ABS(draught - $2)
-									limit 1
-								)`
+	// queries only read the fuel table versions in effect at the given time or during the given period
+	allFuelMaps = ` select f.*, v.valid_from, v.valid_to
+								from fuel f
+								join fuel_versions v on v.id = f.version_id
+								where f.imo = $1
+								and (v.valid_from IS NULL OR v.valid_from <= $2) and (v.valid_to IS NULL OR v.valid_to > $2)
+								order by f.draught, f.beaufort, f.speed, f.wind_sector NULLS FIRST, f.consumption`
+
+	allFuelMapsWithBracketingDrs = ` select f.*, v.valid_from, v.valid_to
+								from fuel f
+								join fuel_versions v on v.id = f.version_id
+								join (
+									SELECT ve.id, (SELECT MAX(b.draught) FROM fuel b WHERE b.version_id = ve.id AND b.draught <= t.target) AS draught
+									FROM fuel_versions ve, unnest($2::float8[]) AS t(target)
+									WHERE ve.imo = $1
+									AND (ve.valid_from IS NULL OR ve.valid_from <= $4) AND (ve.valid_to IS NULL OR ve.valid_to > $3)
+									UNION
+									SELECT ve.id, (SELECT MIN(b.draught) FROM fuel b WHERE b.version_id = ve.id AND b.draught >= t.target) AS draught
+									FROM fuel_versions ve, unnest($2::float8[]) AS t(target)
+									WHERE ve.imo = $1
+									AND (ve.valid_from IS NULL OR ve.valid_from <= $4) AND (ve.valid_to IS NULL OR ve.valid_to > $3)
+								) d on f.version_id = d.id and f.draught = d.draught`
+
+	allFleetFuelMapsWithBracketingDrs = ` select f.*, v.valid_from, v.valid_to
+								from fuel f
+								join fuel_versions v on v.id = f.version_id
+								join (
+									SELECT ve.id, (SELECT MAX(b.draught) FROM fuel b WHERE b.version_id = ve.id AND b.draught <= t.target) AS draught
+									FROM unnest($1::int[], $2::float8[]) AS t(imo, target)
+									JOIN fuel_versions ve ON ve.imo = t.imo
+									WHERE (ve.valid_from IS NULL OR ve.valid_from <= $4) AND (ve.valid_to IS NULL OR ve.valid_to > $3)
+									UNION
+									SELECT ve.id, (SELECT MIN(b.draught) FROM fuel b WHERE b.version_id = ve.id AND b.draught >= t.target) AS draught
+									FROM unnest($1::int[], $2::float8[]) AS t(imo, target)
+									JOIN fuel_versions ve ON ve.imo = t.imo
+									WHERE (ve.valid_from IS NULL OR ve.valid_from <= $4) AND (ve.valid_to IS NULL OR ve.valid_to > $3)
+								) d on f.version_id = d.id and f.draught = d.draught`
+
+	fuelMapDraughtSummaries = ` select v.id, v.imo, v.valid_from, v.valid_to, v.created_at,
+									f.draught, count(*) as rows,
+									min(f.speed) as min_speed, max(f.speed) as max_speed,
+									min(f.beaufort) as min_beaufort, max(f.beaufort) as max_beaufort
+								from fuel_versions v
+								join fuel f on f.version_id = v.id
+								where (v.valid_from IS NULL OR v.valid_from <= now()) and (v.valid_to IS NULL OR v.valid_to > now())
+								group by v.id, f.draught
+								order by v.imo, f.draught`
+
+	fuelMapVersionDraughtSummaries = ` select v.id, v.imo, v.valid_from, v.valid_to, v.created_at,
+									f.draught, count(*) as rows,
+									min(f.speed) as min_speed, max(f.speed) as max_speed,
+									min(f.beaufort) as min_beaufort, max(f.beaufort) as max_beaufort
+								from fuel_versions v
+								join fuel f on f.version_id = v.id
+								where v.imo = $1
+								group by v.id, f.draught
+								order by v.valid_from nulls first, f.draught`
+
+	closeFuelMapVersion = `UPDATE fuel_versions SET valid_to = $2 WHERE imo = $1 AND valid_to IS NULL`
+
+	insertFuelMapVersion = `INSERT INTO fuel_versions (imo, valid_from) VALUES ($1, $2)
+								RETURNING id, imo, valid_from, valid_to, created_at`
+
+	deleteFuelMapVersionRows = `DELETE FROM fuel WHERE version_id = $1`
+
+	// rows of the versions are deleted by the foreign key cascade
+	deleteFuelMap = `DELETE FROM fuel_versions WHERE imo = $1`
+
+	insertFuelMap = `INSERT INTO fuel (imo, version_id, draught, speed, beaufort, consumption, wind_sector)
+						SELECT $1, $2, t.draught, t.speed, t.beaufort, t.consumption, t.wind_sector
+						FROM unnest($3::float8[], $4::float8[], $5::float8[], $6::float8[], $7::varchar[])
+							AS t(draught, speed, beaufort, consumption, wind_sector)`
 )
@@ -2,7 +2,13 @@ package db
 
 import (
 	"context"
+	stderrors "errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/kkr2/vessels/internal/domain"
 	"github.com/kkr2/vessels/internal/errors"
@@ -11,22 +17,43 @@ import (
 
 // Repository Interface
 type VesselRepo interface {
-	GetClosestConsumtion(
+	GetFuelMapWithBracketingDrToTargets(
 		ctx context.Context,
 		imo int,
-		draught float64,
-		speed float64,
-		beaufort float64,
-	) (float64, error)
+		draughts []float64,
+		from time.Time,
+		to time.Time,
+	) ([]*domain.FuelMap, error)
+
+	GetFuelMapsWithBracketingDrToTargets(
+		ctx context.Context,
+		targets map[int][]float64,
+		from time.Time,
+		to time.Time,
+	) (map[int][]*domain.FuelMap, error)
 
-	GetFuelMapWithClosestDrToTarget(
+	GetFuelMap(
 		ctx context.Context,
 		imo int,
-		draught float64,
+		at time.Time,
 	) ([]*domain.FuelMap, error)
+
+	ListFuelMaps(ctx context.Context) ([]*domain.FuelTableSummary, error)
+
+	GetFuelMapVersions(ctx context.Context, imo int) ([]*domain.FuelTableSummary, error)
+
+	SaveFuelMapVersion(
+		ctx context.Context,
+		version *domain.FuelTableVersion,
+		rows []*domain.FuelMap,
+	) (*domain.FuelTableVersion, error)
+
+	DeleteFuelMap(ctx context.Context, imo int) (int64, error)
 }
 
-// Vessels Repository
+// Vessels Repository.
+// Fuel maps are read from the fuel table version in effect at the given time, or from every version
+// in effect during the given period. Rows carry the period of their version.
 type vesselRepo struct {
 	db  *sqlx.DB
 	log logger.Logger
@@ -37,45 +64,280 @@ func NewVesselsRepository(db *sqlx.DB, log logger.Logger) VesselRepo {
 	return &vesselRepo{db: db, log: log}
 }
 
-func (vr *vesselRepo) GetClosestConsumtion(
+// GetFuelMapWithBracketingDrToTargets returns in a single query the fuel map rows of the draughts
+// right below and above every target, so rows of a draught shared by several targets are returned once.
+// The draughts are bracketed in every fuel table version in effect between from and to.
+func (vr *vesselRepo) GetFuelMapWithBracketingDrToTargets(
 	ctx context.Context,
 	imo int,
-	draught float64,
-	speed float64,
-	beaufort float64,
-) (float64, error) {
-
-	operation := errors.Op("db.vesselsRepository.GetClosestConsumption")
-
-	fRow := &domain.FuelMap{}
-
-	if err := vr.db.QueryRowxContext(
-		ctx, findClosestFuelConsumtion,
-		imo,
-		draught,
-		beaufort,
-		speed,
-	).StructScan(fRow); err != nil {
-		return 0, errors.E(operation, errors.KindInternal, err)
+	draughts []float64,
+	from time.Time,
+	to time.Time,
+) ([]*domain.FuelMap, error) {
+
+	operation := errors.Op("db.vesselsRepository.GetFuelMapWithBracketingDrToTargets")
+
+	rows, err := vr.db.QueryxContext(ctx, allFuelMapsWithBracketingDrs, imo, float8Array(draughts), from, to)
+
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return scanFuelMaps(operation, rows)
+}
+
+// GetFuelMapsWithBracketingDrToTargets returns in a single query the fuel map rows of the draughts
+// right below and above the target draughts of several vessels, keyed by imo.
+// The draughts are bracketed in every fuel table version in effect between from and to.
+func (vr *vesselRepo) GetFuelMapsWithBracketingDrToTargets(
+	ctx context.Context,
+	targets map[int][]float64,
+	from time.Time,
+	to time.Time,
+) (map[int][]*domain.FuelMap, error) {
+
+	operation := errors.Op("db.vesselsRepository.GetFuelMapsWithBracketingDrToTargets")
+
+	// targets are flattened into two arrays of the same length, unnested in pairs
+	imos, draughts := []int{}, []float64{}
+	for imo, imoDraughts := range targets {
+		for _, draught := range imoDraughts {
+			imos = append(imos, imo)
+			draughts = append(draughts, draught)
+		}
+	}
+
+	rows, err := vr.db.QueryxContext(ctx, allFleetFuelMapsWithBracketingDrs, intArray(imos), float8Array(draughts), from, to)
+
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
 	}
 
-	return fRow.Consumtion, nil
+	fuelList, err := scanFuelMaps(operation, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	fuelMaps := make(map[int][]*domain.FuelMap, len(targets))
+	for _, fuelMap := range fuelList {
+		fuelMaps[fuelMap.VesselId] = append(fuelMaps[fuelMap.VesselId], fuelMap)
+	}
+	return fuelMaps, nil
 }
-func (vr *vesselRepo) GetFuelMapWithClosestDrToTarget(
+
+// GetFuelMap returns all fuel map rows of the vessel fuel table version in effect at the given time
+func (vr *vesselRepo) GetFuelMap(
 	ctx context.Context,
 	imo int,
-	draught float64,
+	at time.Time,
 ) ([]*domain.FuelMap, error) {
 
-	operation := errors.Op("db.vesselsRepository.GetFuelMapWithClosestDrToTarget")
+	operation := errors.Op("db.vesselsRepository.GetFuelMap")
+
+	rows, err := vr.db.QueryxContext(ctx, allFuelMaps, imo, at)
+
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return scanFuelMaps(operation, rows)
+}
+
+// fuelMapDraughtSummary is a draught layer of a vessel fuel table version as aggregated by the db
+type fuelMapDraughtSummary struct {
+	domain.FuelTableVersion
+	Draught     float64 `db:"draught"`
+	Rows        int     `db:"rows"`
+	MinSpeed    float64 `db:"min_speed"`
+	MaxSpeed    float64 `db:"max_speed"`
+	MinBeaufort float64 `db:"min_beaufort"`
+	MaxBeaufort float64 `db:"max_beaufort"`
+}
+
+// ListFuelMaps returns the summary of the fuel table version in effect of every vessel that has one, ordered by imo
+func (vr *vesselRepo) ListFuelMaps(ctx context.Context) ([]*domain.FuelTableSummary, error) {
+	operation := errors.Op("db.vesselsRepository.ListFuelMaps")
+
+	rows, err := vr.db.QueryxContext(ctx, fuelMapDraughtSummaries)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return scanFuelMapSummaries(operation, rows)
+}
 
-	rows, err := vr.db.QueryxContext(ctx, allFuelMapsWithClosestDr, imo, draught)
+// GetFuelMapVersions returns the summary of every fuel table version of the vessel, oldest first
+func (vr *vesselRepo) GetFuelMapVersions(ctx context.Context, imo int) ([]*domain.FuelTableSummary, error) {
+	operation := errors.Op("db.vesselsRepository.GetFuelMapVersions")
 
+	rows, err := vr.db.QueryxContext(ctx, fuelMapVersionDraughtSummaries, imo)
 	if err != nil {
 		return nil, errors.E(operation, errors.KindInternal, err)
 	}
+
+	return scanFuelMapSummaries(operation, rows)
+}
+
+// scanFuelMapSummaries reads the draught layers of the versions into a summary per version and closes the rows.
+// Layers of a version are expected to be consecutive.
+func scanFuelMapSummaries(operation errors.Op, rows *sqlx.Rows) ([]*domain.FuelTableSummary, error) {
 	defer rows.Close()
 
+	var err error
+	summaries := make([]*domain.FuelTableSummary, 0)
+	var current *domain.FuelTableSummary
+	for rows.Next() {
+		layer := &fuelMapDraughtSummary{}
+		if err = rows.StructScan(layer); err != nil {
+			return nil, errors.E(operation, errors.KindInternal, err)
+		}
+		if current == nil || current.ID != layer.ID {
+			current = &domain.FuelTableSummary{
+				FuelTableVersion: layer.FuelTableVersion,
+				Draughts:         []float64{},
+				MinSpeed:         layer.MinSpeed,
+				MaxSpeed:         layer.MaxSpeed,
+				MinBeaufort:      layer.MinBeaufort,
+				MaxBeaufort:      layer.MaxBeaufort,
+			}
+			summaries = append(summaries, current)
+		}
+		current.Rows += layer.Rows
+		current.Draughts = append(current.Draughts, layer.Draught)
+		current.MinSpeed = math.Min(current.MinSpeed, layer.MinSpeed)
+		current.MaxSpeed = math.Max(current.MaxSpeed, layer.MaxSpeed)
+		current.MinBeaufort = math.Min(current.MinBeaufort, layer.MinBeaufort)
+		current.MaxBeaufort = math.Max(current.MaxBeaufort, layer.MaxBeaufort)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	return summaries, nil
+}
+
+// SaveFuelMapVersion saves the rows of a fuel table version in a single transaction, so readers see
+// either the old or the new table. Rows of an existing version are replaced. A new version closes the
+// open version of the vessel at its start.
+func (vr *vesselRepo) SaveFuelMapVersion(
+	ctx context.Context,
+	version *domain.FuelTableVersion,
+	rows []*domain.FuelMap,
+) (*domain.FuelTableVersion, error) {
+
+	operation := errors.Op("db.vesselsRepository.SaveFuelMapVersion")
+
+	tx, err := vr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+	// rollback is a no-op once committed
+	defer tx.Rollback()
+
+	saved := version
+	if version.ID == uuid.Nil {
+		if version.ValidFrom != nil {
+			if _, err = tx.ExecContext(ctx, closeFuelMapVersion, version.IMO, version.ValidFrom); err != nil {
+				return nil, versionError(operation, err)
+			}
+		}
+		saved = &domain.FuelTableVersion{}
+		if err = tx.QueryRowxContext(ctx, insertFuelMapVersion, version.IMO, version.ValidFrom).StructScan(saved); err != nil {
+			return nil, versionError(operation, err)
+		}
+	} else if _, err = tx.ExecContext(ctx, deleteFuelMapVersionRows, version.ID); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	draughts := make([]float64, 0, len(rows))
+	speeds := make([]float64, 0, len(rows))
+	beauforts := make([]float64, 0, len(rows))
+	consumptions := make([]float64, 0, len(rows))
+	sectors := make([]*string, 0, len(rows))
+	for _, row := range rows {
+		draughts = append(draughts, row.Draught)
+		speeds = append(speeds, row.Speed)
+		beauforts = append(beauforts, row.Weather)
+		consumptions = append(consumptions, row.Consumtion)
+		var sector *string
+		if row.WindSector != nil {
+			s := string(*row.WindSector)
+			sector = &s
+		}
+		sectors = append(sectors, sector)
+	}
+
+	if _, err = tx.ExecContext(
+		ctx, insertFuelMap,
+		saved.IMO,
+		saved.ID,
+		float8Array(draughts),
+		float8Array(speeds),
+		float8Array(beauforts),
+		float8Array(consumptions),
+		textArray(sectors),
+	); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.E(operation, errors.KindInternal, err)
+	}
+	return saved, nil
+}
+
+// DeleteFuelMap deletes all fuel table versions of the vessel and returns how many were deleted
+func (vr *vesselRepo) DeleteFuelMap(ctx context.Context, imo int) (int64, error) {
+	operation := errors.Op("db.vesselsRepository.DeleteFuelMap")
+
+	res, err := vr.db.ExecContext(ctx, deleteFuelMap, imo)
+	if err != nil {
+		return 0, errors.E(operation, errors.KindInternal, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.E(operation, errors.KindInternal, err)
+	}
+	return deleted, nil
+}
+
+// float8Array formats values as a postgres array literal, so it can be passed as text and cast to float8[]
+func float8Array(values []float64) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		formatted = append(formatted, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return "{" + strings.Join(formatted, ",") + "}"
+}
+
+// intArray formats values as a postgres array literal, so it can be passed as text and cast to int[]
+func intArray(values []int) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		formatted = append(formatted, strconv.Itoa(v))
+	}
+	return "{" + strings.Join(formatted, ",") + "}"
+}
+
+// textArray formats values as a postgres array literal of quoted strings, nil values are NULL
+func textArray(values []*string) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			formatted = append(formatted, "NULL")
+			continue
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(*v)
+		formatted = append(formatted, `"`+escaped+`"`)
+	}
+	return "{" + strings.Join(formatted, ",") + "}"
+}
+
+// scanFuelMaps reads all fuel map rows and closes them
+func scanFuelMaps(operation errors.Op, rows *sqlx.Rows) ([]*domain.FuelMap, error) {
+	defer rows.Close()
+
+	var err error
 	fuelList := make([]*domain.FuelMap, 0)
 	for rows.Next() {
 		fuelMap := &domain.FuelMap{}
@@ -90,5 +352,23 @@ func (vr *vesselRepo) GetFuelMapWithClosestDrToTarget(
 	}
 
 	return fuelList, nil
+}
+
+// SQLSTATE codes of the constraints on the periods of fuel table versions
+const (
+	checkViolation     = "23514"
+	exclusionViolation = "23P01"
+)
 
+// versionError is the error of writing a fuel table version, a version whose period is empty
+// or overlaps another version of the vessel is bad input, other errors are internal
+func versionError(operation errors.Op, err error) error {
+	var state interface{ SQLState() string }
+	if stderrors.As(err, &state) {
+		switch state.SQLState() {
+		case checkViolation, exclusionViolation:
+			return errors.E(operation, errors.KindBadInput, "fuel table version overlaps another version of the vessel")
+		}
+	}
+	return errors.E(operation, errors.KindInternal, err)
 }
@@ -0,0 +1,177 @@
+package externalrpc
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/errors"
+)
+
+const (
+	defaultHourlyWeatherCacheSize = 100000
+	defaultWeatherTimeout         = 10 * time.Second
+)
+
+// hourlyCache keeps weather by rounded position and hour.
+// It holds at most size samples, the least recently used sample is evicted to add a new one.
+type hourlyCache struct {
+	size    int
+	entries map[string]*list.Element
+	recent  *list.List
+	mu      sync.Mutex
+}
+
+// hourlyEntry is a cached sample, kept in the recency list of the cache
+type hourlyEntry struct {
+	key     string
+	weather *domain.Weather
+}
+
+func newHourlyCache(size int) *hourlyCache {
+	return &hourlyCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+}
+
+// get returns the cached sample of key and marks it as the most recently used
+func (c *hourlyCache) get(key string) (*domain.Weather, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*hourlyEntry).weather, true
+}
+
+// add caches the sample of key, evicting the least recently used samples when full
+func (c *hourlyCache) add(key string, weather *domain.Weather) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, exists := c.entries[key]; exists {
+		element.Value.(*hourlyEntry).weather = weather
+		c.recent.MoveToFront(element)
+		return
+	}
+	for c.recent.Len() >= c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*hourlyEntry).key)
+	}
+	c.entries[key] = c.recent.PushFront(&hourlyEntry{key: key, weather: weather})
+}
+
+// hourlyWeatherClient gets weather by position and time from the hourly weather api.
+// Day only requests are still served by the day only client.
+type hourlyWeatherClient struct {
+	*weatherClient
+	hourly *hourlyCache
+	client *http.Client
+}
+
+// newHourlyWeatherClient creates an hourly weather client on top of the day only client
+func newHourlyWeatherClient(dayClient *weatherClient) *hourlyWeatherClient {
+	size := dayClient.cfg.Server.HourlyWeatherCacheSize
+	if size <= 0 {
+		size = defaultHourlyWeatherCacheSize
+	}
+	timeout := defaultWeatherTimeout
+	if dayClient.cfg.Server.WeatherTimeoutInSeconds > 0 {
+		timeout = time.Duration(dayClient.cfg.Server.WeatherTimeoutInSeconds) * time.Second
+	}
+	return &hourlyWeatherClient{
+		weatherClient: dayClient,
+		hourly:        newHourlyCache(size),
+		client:        &http.Client{Timeout: timeout},
+	}
+}
+
+// GetWeatherAt receives weather for the position (rounded to 0.1 degree) and hour and updates cache
+func (wc *hourlyWeatherClient) GetWeatherAt(ctx context.Context, latitude float64, longitude float64, t time.Time) (*domain.Weather, error) {
+	operation := errors.Op("externalrpc.hourlyWeatherRepository.GetWeatherAt")
+	hour := t.UTC().Truncate(time.Hour)
+	key := fmt.Sprintf("%.1f:%.1f:%s", latitude, longitude, hour.Format(time.RFC3339))
+	if cachedRes, exists := wc.hourly.get(key); exists {
+		return cachedRes, nil
+	}
+	res, err := wc.makeHourlyCall(ctx, latitude, longitude, hour)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindExternalRPC, err)
+	}
+	// update cache
+	wc.hourly.add(key, res)
+
+	return res, nil
+}
+
+type HourlyReqBody struct {
+	Latitude  float64   `json:"Latitude"`
+	Longitude float64   `json:"Longitude"`
+	Time      time.Time `json:"Time"`
+}
+type HourlyResBody struct {
+	Beaufort      float64  `json:"Beaufort"`
+	WindSpeed     float64  `json:"WindSpeed"`
+	WindDirection *float64 `json:"WindDirection"`
+	WaveHeight    float64  `json:"WaveHeight"`
+}
+
+// makeHourlyCall gets weather at a position and hour from the external hourly weather system
+func (wc *hourlyWeatherClient) makeHourlyCall(ctx context.Context, latitude float64, longitude float64, hour time.Time) (*domain.Weather, error) {
+	body := &HourlyReqBody{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Time:      hour,
+	}
+	payloadBuf := new(bytes.Buffer)
+	if err := json.NewEncoder(payloadBuf).Encode(body); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", wc.cfg.Server.HourlyWeatherApiUrl, payloadBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-api-key", wc.cfg.Server.WeatherSecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := wc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hourly weather api returned %s", res.Status)
+	}
+
+	resbody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var rb = new(HourlyResBody)
+	if err = json.Unmarshal(resbody, &rb); err != nil {
+		return nil, err
+	}
+
+	return &domain.Weather{
+		Beaufort:           rb.Beaufort,
+		WindSpeedInKnot:    rb.WindSpeed,
+		WindDirection:      rb.WindDirection,
+		WaveHeightInMeters: rb.WaveHeight,
+	}, nil
+}
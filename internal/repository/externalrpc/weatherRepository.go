@@ -11,12 +11,14 @@ import (
 	"time"
 
 	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/domain"
 	"github.com/kkr2/vessels/internal/errors"
 	"github.com/kkr2/vessels/internal/logger"
 )
 
 type WeatherClient interface {
 	GetWeatherForDay(ctx context.Context, day time.Time) (float64, error) //beaufort
+	GetWeatherAt(ctx context.Context, latitude float64, longitude float64, t time.Time) (*domain.Weather, error)
 }
 
 // Ths implementation of cache is not safe. Used as an example
@@ -33,15 +35,20 @@ type weatherClient struct {
 	logger logger.Logger
 }
 
-// NewWeatherClient creates new weather client and creates new cache
+// NewWeatherClient creates new weather client and creates new cache.
+// The hourly client is used when an hourly weather api is configured, the day only client otherwise.
 func NewWeatherClient(cfg *config.Config, log logger.Logger) WeatherClient {
-	return &weatherClient{
+	dayClient := &weatherClient{
 		cfg:    cfg,
 		logger: log,
 		cache: kvcache{
 			data: make(map[string]float64),
 		},
 	}
+	if cfg.Server.HourlyWeatherApiUrl != "" {
+		return newHourlyWeatherClient(dayClient)
+	}
+	return dayClient
 }
 
 // GetWeatherForDay receives weather update for the required day and updates cache
@@ -66,6 +73,16 @@ func (wc *weatherClient) GetWeatherForDay(ctx context.Context, t time.Time) (flo
 	return res, nil
 }
 
+// GetWeatherAt adapts the day only weather to a position and time.
+// Every position gets the beaufort of the day, wind speed and wave height are estimated from it.
+func (wc *weatherClient) GetWeatherAt(ctx context.Context, latitude float64, longitude float64, t time.Time) (*domain.Weather, error) {
+	beaufort, err := wc.GetWeatherForDay(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return domain.WeatherFromBeaufort(beaufort), nil
+}
+
 type ReqBody struct {
 	Date string `json:"Date"`
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/kkr2/vessels/internal/delivery"
+	"github.com/kkr2/vessels/internal/emissions"
 	"github.com/kkr2/vessels/internal/repository/db"
 	"github.com/kkr2/vessels/internal/repository/externalrpc"
 	"github.com/kkr2/vessels/internal/service"
@@ -14,13 +15,25 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 
 	// Init repositories
 	vRepo := db.NewVesselsRepository(s.db, s.logger)
+	rRepo := db.NewRegistryRepository(s.db, s.logger)
 	vClient := externalrpc.NewWeatherClient(s.cfg, s.logger)
+	eRegistry := emissions.NewRegistry(s.cfg)
 
 	// Init useCases
-	vService := service.NewVesselsService(vRepo, vClient, s.logger)
+	vService := service.NewVesselsService(s.cfg, vRepo, rRepo, vClient, eRegistry, s.logger)
+	rService := service.NewRegistryService(rRepo, eRegistry, s.logger)
+	cService := service.NewCIIService(vService, rRepo, s.logger)
+	fService := service.NewFuelEUService(vService, eRegistry, s.logger)
+	voyService := service.NewVoyageService(s.cfg, vRepo, rRepo, vClient, eRegistry, s.logger)
+	ftService := service.NewFuelTableService(s.cfg, vRepo, rRepo, s.logger)
 
 	// Init handlers
 	vHandler := delivery.NewVesselsHandlers(s.cfg, vService, s.logger)
+	rHandler := delivery.NewRegistryHandlers(s.cfg, rService, s.logger)
+	cHandler := delivery.NewCIIHandlers(s.cfg, cService, s.logger)
+	fHandler := delivery.NewFuelEUHandlers(s.cfg, fService, s.logger)
+	voyHandler := delivery.NewVoyageHandlers(s.cfg, voyService, s.logger)
+	ftHandler := delivery.NewFuelTableHandlers(s.cfg, ftService, s.logger)
 
 	v1 := e.Group("/api/v1")
 
@@ -28,6 +41,11 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	vesselGroup := v1.Group("/vessels")
 
 	delivery.MapVesselRoutes(vesselGroup, vHandler)
+	delivery.MapRegistryRoutes(vesselGroup, rHandler)
+	delivery.MapCIIRoutes(vesselGroup, cHandler)
+	delivery.MapFuelEURoutes(vesselGroup, fHandler)
+	delivery.MapVoyageRoutes(vesselGroup, voyHandler)
+	delivery.MapFuelTableRoutes(vesselGroup, ftHandler)
 
 	health.GET("", func(c echo.Context) error {
 		s.logger.Infof("Health check RequestID: %s", c.Response().Header().Get(echo.HeaderXRequestID))
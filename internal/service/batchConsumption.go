@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/errors"
+)
+
+// defaultBatchConcurrency is the number of batch entries calculated at the same time when not configured
+const defaultBatchConcurrency = 8
+
+// batchJob is a batch entry whose draughts are resolved, waiting for its data.
+// Its options are resolved once the particulars of its vessel are loaded.
+type batchJob struct {
+	entry    *domain.BatchEntry
+	settings *consumptionSettings
+	result   *domain.BatchResult
+}
+
+// GetBatchConsumption calculates the routes consumption of many vessels.
+// Fuel maps and vessel particulars of all entries are loaded in one round of queries, entries are
+// then calculated concurrently. An entry failing does not fail the others, its error is on its result.
+func (vs *vesselService) GetBatchConsumption(
+	ctx context.Context,
+	entries []*domain.BatchEntry,
+) (map[string]*domain.BatchResult, error) {
+	operation := errors.Op("service.vesselsService.GetBatchConsumption")
+
+	results := make(map[string]*domain.BatchResult, len(entries))
+	targets := make(map[int][]float64)
+	routes := []*domain.Route{}
+	jobs := make([]*batchJob, 0, len(entries))
+	for _, entry := range entries {
+		if _, exists := results[entry.ID]; exists {
+			return nil, errors.E(operation, errors.KindBadInput, fmt.Sprintf("duplicate entry id %q", entry.ID))
+		}
+		result := &domain.BatchResult{}
+		results[entry.ID] = result
+
+		draughts, err := distinctDraughts(entry.Draught, entry.Routes)
+		if err != nil {
+			result.Err = errors.E(operation, errors.KindBadInput, err)
+			continue
+		}
+		targets[entry.IMO] = append(targets[entry.IMO], draughts...)
+		routes = append(routes, entry.Routes...)
+		jobs = append(jobs, &batchJob{entry: entry, result: result})
+	}
+	if len(jobs) == 0 {
+		return results, nil
+	}
+
+	// one query for the fuel maps and one for the particulars of all vessels.
+	// Fuel table versions in effect at any time of the entries are loaded.
+	from, to := routesPeriod(routes)
+	fuelMaps, err := vs.fuelRepo.GetFuelMapsWithBracketingDrToTargets(ctx, targets, from, to)
+	if err != nil {
+		return nil, err
+	}
+	imos := make([]int, 0, len(targets))
+	for imo := range targets {
+		imos = append(imos, imo)
+	}
+	vessels, err := vs.registryRepo.GetVessels(ctx, imos)
+	if err != nil {
+		return nil, err
+	}
+
+	// volumes are read only once built, so entries of the same vessel share them
+	volumes := make(map[int]fuelVersions, len(targets))
+	for imo := range targets {
+		volumes[imo] = newFuelVersions(fuelMaps[imo])
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, vs.batchConcurrency())
+	for _, job := range jobs {
+		job := job
+		if _, exists := vessels[job.entry.IMO]; !exists {
+			job.result.Err = errors.E(operation, errors.KindNotFound, "vessel not registered")
+			continue
+		}
+		if len(fuelMaps[job.entry.IMO]) == 0 {
+			job.result.Err = errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+			continue
+		}
+		settings, err := vs.resolveOptions(job.entry.Options, vessels[job.entry.IMO])
+		if err != nil {
+			job.result.Err = err
+			continue
+		}
+		job.settings = settings
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			job.result.Routes, job.result.Err = vs.calculateRoutes(
+				ctx,
+				volumes[job.entry.IMO],
+				vessels[job.entry.IMO],
+				job.entry.Draught,
+				job.entry.Routes,
+				job.settings,
+			)
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// batchConcurrency resolves the number of batch entries calculated at the same time
+func (vs *vesselService) batchConcurrency() int {
+	if vs.cfg.Calculation.BatchConcurrency > 0 {
+		return vs.cfg.Calculation.BatchConcurrency
+	}
+	return defaultBatchConcurrency
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/emissions"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/repository/db"
+)
+
+// CIIService is an interface for carbon intensity usecases
+type CIIService interface {
+	GetCIIRating(
+		ctx context.Context,
+		imo int,
+		year int,
+		drought float64,
+		vesselRoutes []*domain.Route,
+		opts domain.ConsumptionOptions,
+	) (*domain.CIIRating, error)
+}
+
+// ciiService is a concrete implementation of the above interface
+type ciiService struct {
+	vesselService VesselService
+	registryRepo  db.RegistryRepo
+	logger        logger.Logger
+}
+
+// NewCIIService makes a new CII service provided the external dependencies
+func NewCIIService(vs VesselService, rr db.RegistryRepo, log logger.Logger) CIIService {
+	return &ciiService{
+		vesselService: vs,
+		registryRepo:  rr,
+		logger:        log,
+	}
+}
+
+// GetCIIRating rates the vessel for the year based on the CO2 and distance of the routes sailed in that year
+func (cs *ciiService) GetCIIRating(
+	ctx context.Context,
+	imo int,
+	year int,
+	drought float64,
+	vesselRoutes []*domain.Route,
+	opts domain.ConsumptionOptions,
+) (*domain.CIIRating, error) {
+	operation := errors.Op("service.ciiService.GetCIIRating")
+
+	vessel, err := cs.registryRepo.GetVessel(ctx, imo)
+	if err != nil {
+		return nil, err
+	}
+
+	routesConsumption, err := cs.vesselService.GetRoutesConsumtion(ctx, imo, drought, vesselRoutes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCO2, totalDistance := 0.0, 0.0
+	for _, rc := range routesConsumption {
+		totalCO2 += rc.ConsumptionInCO2
+		totalDistance += rc.TotalDistanceInNM()
+	}
+
+	rating, err := emissions.CalculateCII(vessel, year, totalCO2, totalDistance)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	return rating, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"math"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+const (
+	// minCurveExponent and maxCurveExponent bound the speed exponent searched when fitting a curve
+	minCurveExponent = 1.0
+	maxCurveExponent = 5.0
+	// curveExponentStep is the resolution of the speed exponent
+	curveExponentStep = 0.01
+)
+
+// fitConsumptionCurve fits consumption = a * v^n + b * beaufort to the fuel table rows of a draught.
+// For every exponent n on a grid a and b are solved by least squares, the exponent with the smallest
+// squared error is kept. Returns nil when there are no rows with a positive speed.
+func fitConsumptionCurve(draught float64, rows []*domain.FuelMap) *domain.ConsumptionCurve {
+	samples := make([]*domain.FuelMap, 0, len(rows))
+	for _, row := range rows {
+		if row.Speed > 0 {
+			samples = append(samples, row)
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var best *domain.ConsumptionCurve
+	bestError := math.Inf(1)
+	for n := minCurveExponent; n <= maxCurveExponent+curveExponentStep/2; n += curveExponentStep {
+		a, b := solveCurveCoefficients(samples, n)
+		curve := &domain.ConsumptionCurve{Coefficient: a, Exponent: n, WeatherCoefficient: b}
+		squaredError := 0.0
+		for _, row := range samples {
+			residual := row.Consumtion - curve.Consumption(row.Speed, row.Weather)
+			squaredError += residual * residual
+		}
+		if squaredError < bestError {
+			best, bestError = curve, squaredError
+		}
+	}
+
+	best.Draught = draught
+	best.Rows = len(samples)
+	best.RSquared = rSquared(samples, bestError)
+	best.MinSpeed, best.MaxSpeed = samples[0].Speed, samples[0].Speed
+	best.MinWeather, best.MaxWeather = samples[0].Weather, samples[0].Weather
+	for _, row := range samples {
+		best.MinSpeed = math.Min(best.MinSpeed, row.Speed)
+		best.MaxSpeed = math.Max(best.MaxSpeed, row.Speed)
+		best.MinWeather = math.Min(best.MinWeather, row.Weather)
+		best.MaxWeather = math.Max(best.MaxWeather, row.Weather)
+	}
+
+	return best
+}
+
+// solveCurveCoefficients solves the normal equations of consumption = a * v^n + b * beaufort for a fixed n.
+// When the rows have a single weather the weather term can not be fitted and only a is solved.
+func solveCurveCoefficients(rows []*domain.FuelMap, n float64) (float64, float64) {
+	var xx, xw, ww, xy, wy float64
+	for _, row := range rows {
+		x := math.Pow(row.Speed, n)
+		xx += x * x
+		xw += x * row.Weather
+		ww += row.Weather * row.Weather
+		xy += x * row.Consumtion
+		wy += row.Weather * row.Consumtion
+	}
+
+	determinant := xx*ww - xw*xw
+	if math.Abs(determinant) <= 1e-9*xx*ww {
+		if xx == 0 {
+			return 0, 0
+		}
+		return xy / xx, 0
+	}
+	return (xy*ww - xw*wy) / determinant, (xx*wy - xw*xy) / determinant
+}
+
+// rSquared returns the coefficient of determination given the squared error of the fit
+func rSquared(rows []*domain.FuelMap, squaredError float64) float64 {
+	mean := 0.0
+	for _, row := range rows {
+		mean += row.Consumtion
+	}
+	mean /= float64(len(rows))
+
+	total := 0.0
+	for _, row := range rows {
+		total += (row.Consumtion - mean) * (row.Consumtion - mean)
+	}
+	if total == 0 {
+		return 1
+	}
+	return 1 - squaredError/total
+}
+
+// extrapolateSpeed returns the consumption at speed outside of the table from the consumption at the edge speed,
+// following the fitted curve so the value is continuous at the edge of the table.
+// Without a usable curve the edge consumption is returned.
+func extrapolateSpeed(curve *domain.ConsumptionCurve, edgeSpeed float64, edgeConsumption float64, speed float64) float64 {
+	if curve == nil || curve.Coefficient <= 0 || speed <= 0 {
+		return edgeConsumption
+	}
+	delta := curve.Coefficient * (math.Pow(speed, curve.Exponent) - math.Pow(edgeSpeed, curve.Exponent))
+	return math.Max(0, edgeConsumption+delta)
+}
+
+// extrapolateWeather returns the consumption at weather outside of the table from the consumption at the edge weather.
+// Without a usable curve the edge consumption is returned.
+func extrapolateWeather(curve *domain.ConsumptionCurve, edgeWeather float64, edgeConsumption float64, weather float64) float64 {
+	if curve == nil || curve.WeatherCoefficient <= 0 {
+		return edgeConsumption
+	}
+	return math.Max(0, edgeConsumption+curve.WeatherCoefficient*(weather-edgeWeather))
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// curveRows returns the rows of consumption = a * v^n + b * beaufort on a grid of speeds and beauforts
+func curveRows(a, n, b float64, speeds, weathers []float64) []*domain.FuelMap {
+	rows := []*domain.FuelMap{}
+	for _, weather := range weathers {
+		for _, speed := range speeds {
+			rows = append(rows, &domain.FuelMap{
+				Draught:    10,
+				Speed:      speed,
+				Weather:    weather,
+				Consumtion: a*math.Pow(speed, n) + b*weather,
+			})
+		}
+	}
+	return rows
+}
+
+func TestFitConsumptionCurve(t *testing.T) {
+	speeds := []float64{8, 9, 10, 11, 12, 13, 14}
+	tests := []struct {
+		name                       string
+		rows                       []*domain.FuelMap
+		wantCoefficient            float64
+		wantExponent               float64
+		wantWeatherCoefficient     float64
+		wantMinSpeed, wantMaxSpeed float64
+	}{
+		{
+			name:                   "cubic with weather",
+			rows:                   curveRows(0.005, 3, 1.5, speeds, []float64{0, 2, 4}),
+			wantCoefficient:        0.005,
+			wantExponent:           3,
+			wantWeatherCoefficient: 1.5,
+			wantMinSpeed:           8, wantMaxSpeed: 14,
+		},
+		{
+			name:                   "single weather fits no weather term",
+			rows:                   curveRows(0.02, 2.5, 0, speeds, []float64{3}),
+			wantCoefficient:        0.02,
+			wantExponent:           2.5,
+			wantWeatherCoefficient: 0,
+			wantMinSpeed:           8, wantMaxSpeed: 14,
+		},
+		{
+			name:                   "rows at speed 0 are left out",
+			rows:                   append(curveRows(0.01, 2, 0.5, speeds, []float64{0, 5}), &domain.FuelMap{Speed: 0, Consumtion: 4}),
+			wantCoefficient:        0.01,
+			wantExponent:           2,
+			wantWeatherCoefficient: 0.5,
+			wantMinSpeed:           8, wantMaxSpeed: 14,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curve := fitConsumptionCurve(10, tt.rows)
+			if curve == nil {
+				t.Fatal("fitConsumptionCurve returned nil")
+			}
+			if math.Abs(curve.Exponent-tt.wantExponent) > 1e-6 {
+				t.Errorf("Exponent = %v, want %v", curve.Exponent, tt.wantExponent)
+			}
+			if math.Abs(curve.Coefficient-tt.wantCoefficient) > 1e-6 {
+				t.Errorf("Coefficient = %v, want %v", curve.Coefficient, tt.wantCoefficient)
+			}
+			if math.Abs(curve.WeatherCoefficient-tt.wantWeatherCoefficient) > 1e-6 {
+				t.Errorf("WeatherCoefficient = %v, want %v", curve.WeatherCoefficient, tt.wantWeatherCoefficient)
+			}
+			if math.Abs(curve.RSquared-1) > 1e-6 {
+				t.Errorf("RSquared = %v, want 1", curve.RSquared)
+			}
+			if curve.MinSpeed != tt.wantMinSpeed || curve.MaxSpeed != tt.wantMaxSpeed {
+				t.Errorf("speed range = %v-%v, want %v-%v", curve.MinSpeed, curve.MaxSpeed, tt.wantMinSpeed, tt.wantMaxSpeed)
+			}
+			if curve.Draught != 10 {
+				t.Errorf("Draught = %v, want 10", curve.Draught)
+			}
+		})
+	}
+}
+
+func TestFitConsumptionCurveWithoutSpeed(t *testing.T) {
+	rows := []*domain.FuelMap{{Speed: 0, Weather: 2, Consumtion: 3}}
+	if curve := fitConsumptionCurve(10, rows); curve != nil {
+		t.Errorf("fitConsumptionCurve = %+v, want nil", curve)
+	}
+}
+
+func TestExtrapolateSpeed(t *testing.T) {
+	cubic := &domain.ConsumptionCurve{Coefficient: 0.01, Exponent: 3}
+	tests := []struct {
+		name  string
+		curve *domain.ConsumptionCurve
+		speed float64
+		want  float64
+	}{
+		{name: "above the edge", curve: cubic, speed: 16, want: 30 + 0.01*(4096-3375)},
+		{name: "below the edge", curve: cubic, speed: 14, want: 30 + 0.01*(2744-3375)},
+		{name: "at the edge", curve: cubic, speed: 15, want: 30},
+		{name: "never negative", curve: cubic, speed: 1, want: 0},
+		{name: "without curve", speed: 16, want: 30},
+		{name: "curve without positive coefficient", curve: &domain.ConsumptionCurve{Coefficient: -0.01, Exponent: 3}, speed: 16, want: 30},
+		{name: "speed 0", curve: cubic, speed: 0, want: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extrapolateSpeed(tt.curve, 15, 30, tt.speed); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("extrapolateSpeed(%v) = %v, want %v", tt.speed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtrapolateWeather(t *testing.T) {
+	curve := &domain.ConsumptionCurve{Coefficient: 0.01, Exponent: 3, WeatherCoefficient: 1.2}
+	tests := []struct {
+		name    string
+		curve   *domain.ConsumptionCurve
+		weather float64
+		want    float64
+	}{
+		{name: "above the edge", curve: curve, weather: 8, want: 30 + 1.2*2},
+		{name: "below the edge", curve: curve, weather: 5, want: 30 - 1.2},
+		{name: "never negative", curve: &domain.ConsumptionCurve{WeatherCoefficient: 10}, weather: 0, want: 0},
+		{name: "without curve", weather: 8, want: 30},
+		{name: "curve without weather term", curve: &domain.ConsumptionCurve{Coefficient: 0.01, Exponent: 3}, weather: 8, want: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extrapolateWeather(tt.curve, 6, 30, tt.weather); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("extrapolateWeather(%v) = %v, want %v", tt.weather, got, tt.want)
+			}
+		})
+	}
+}
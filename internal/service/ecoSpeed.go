@@ -0,0 +1,102 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// calculateEcoAdvisory finds the constant speed that minimises fuel for the route while still
+// arriving at the final timestamp. Legs keep the weather calculated for the sailed route.
+// Only sailing legs are re-sped, time spent manoeuvring, anchored or berthed is kept as sailed.
+// Faster speeds arrive early and the vessel waits anchored, the waiting time is charged the anchored auxiliary consumption.
+// Returns nil when the route has no sailing distance or duration.
+func calculateEcoAdvisory(
+	versions fuelVersions,
+	factors domain.DirectionalFactors,
+	auxiliary domain.AuxiliaryConsumption,
+	route *domain.RouteConsumption,
+	co2Factor float64,
+) *domain.EcoAdvisory {
+	totalDistance, totalMinutes := 0.0, 0.0
+	for _, ptp := range route.Legs {
+		if ptp.State != domain.LegSailing {
+			continue
+		}
+		totalDistance += ptp.DistanceInNM
+		totalMinutes += ptp.TimeDiffInMins
+	}
+	if totalDistance <= 0 || totalMinutes <= 0 {
+		return nil
+	}
+
+	// any slower constant speed would miss the final timestamp
+	requiredSpeed := totalDistance * 60.0 / totalMinutes
+
+	bestSpeed := requiredSpeed
+	bestConsumption := consumptionAtConstantSpeed(versions, factors, auxiliary, route.Legs, requiredSpeed)
+	for _, speed := range versions.speeds() {
+		if speed <= requiredSpeed {
+			continue
+		}
+		consumption := consumptionAtConstantSpeed(versions, factors, auxiliary, route.Legs, speed)
+		if consumption < bestConsumption {
+			bestSpeed, bestConsumption = speed, consumption
+		}
+	}
+
+	saving := route.ConsumtionInMetricTons - bestConsumption
+	return &domain.EcoAdvisory{
+		SpeedInKnot:            bestSpeed,
+		ConsumtionInMetricTons: bestConsumption,
+		ConsumptionInCO2:       bestConsumption * co2Factor,
+		SavingInMetricTons:     saving,
+		SavingInCO2:            saving * co2Factor,
+	}
+}
+
+// consumptionAtConstantSpeed calculates route consumption if every sailing leg is sailed at the given speed.
+// The time saved on the sailed schedule is spent waiting anchored and charged the anchored auxiliary consumption.
+func consumptionAtConstantSpeed(
+	versions fuelVersions,
+	factors domain.DirectionalFactors,
+	auxiliary domain.AuxiliaryConsumption,
+	legs []*domain.PointToPoint,
+	speed float64,
+) float64 {
+	total, slackInMins := 0.0, 0.0
+	for _, ptp := range legs {
+		if ptp.State != domain.LegSailing {
+			total += ptp.ExactConsumtion
+			continue
+		}
+		dailyConsumption, _ := versions.forLeg(ptp).windConsumption(ptp.Draught, speed, ptp.AvgWeatherInBeaufort, ptp.WindSector, factors)
+		daysAtSea := ptp.DistanceInNM / speed / 24.0
+		total += dailyConsumption * daysAtSea
+		slackInMins += ptp.TimeDiffInMins - daysAtSea*24*60
+	}
+	if slackInMins > 0 {
+		total += auxiliary.ForState(domain.LegAnchored) * slackInMins / 60.0 / 24.0
+	}
+	return total
+}
+
+// speeds returns all distinct speeds of the volume sorted ascending
+func (fv *fuelVolume) speeds() []float64 {
+	unique := make(map[float64]struct{})
+	for _, surface := range fv.surfaces {
+		for _, layer := range surface.layers {
+			for _, row := range layer.rows {
+				unique[row.Speed] = struct{}{}
+			}
+		}
+	}
+
+	speeds := make([]float64, 0, len(unique))
+	for speed := range unique {
+		speeds = append(speeds, speed)
+	}
+	sort.Float64s(speeds)
+
+	return speeds
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/emissions"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/logger"
+)
+
+// fuelMixTolerance is the allowed deviation of the fuel mix shares sum from 1
+const fuelMixTolerance = 1e-6
+
+// FuelEUService is an interface for FuelEU Maritime usecases
+type FuelEUService interface {
+	GetFuelEUBalance(
+		ctx context.Context,
+		imo int,
+		year int,
+		drought float64,
+		vesselRoutes []*domain.Route,
+		fuelMix map[string]float64,
+		opts domain.ConsumptionOptions,
+	) (*domain.FuelEUBalance, error)
+}
+
+// fuelEUService is a concrete implementation of the above interface
+type fuelEUService struct {
+	vesselService VesselService
+	emissions     *emissions.Registry
+	logger        logger.Logger
+}
+
+// NewFuelEUService makes a new FuelEU service provided the external dependencies
+func NewFuelEUService(vs VesselService, er *emissions.Registry, log logger.Logger) FuelEUService {
+	return &fuelEUService{
+		vesselService: vs,
+		emissions:     er,
+		logger:        log,
+	}
+}
+
+// GetFuelEUBalance calculates the GHG intensity and compliance balance of the routes for the reporting year.
+// Route consumption is split between fuels by the mass shares of fuelMix, when empty the fuel of the routes is used.
+func (fs *fuelEUService) GetFuelEUBalance(
+	ctx context.Context,
+	imo int,
+	year int,
+	drought float64,
+	vesselRoutes []*domain.Route,
+	fuelMix map[string]float64,
+	opts domain.ConsumptionOptions,
+) (*domain.FuelEUBalance, error) {
+	operation := errors.Op("service.fuelEUService.GetFuelEUBalance")
+
+	routesConsumption, err := fs.vesselService.GetRoutesConsumtion(ctx, imo, drought, vesselRoutes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	for _, rc := range routesConsumption {
+		total += rc.ConsumtionInMetricTons
+	}
+
+	// without a fuel mix the routes are burnt in the fuel they were calculated with,
+	// the requested one, the main fuel of the vessel or the configured default
+	fuelType := opts.FuelType
+	if len(routesConsumption) > 0 {
+		fuelType = string(routesConsumption[0].FuelType)
+	}
+	fuels, err := fs.splitByFuel(total, fuelMix, fuelType)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	balance, err := fs.emissions.CalculateFuelEU(year, fuels)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	return balance, nil
+}
+
+// splitByFuel splits total consumption between fuels given their mass shares
+func (fs *fuelEUService) splitByFuel(total float64, fuelMix map[string]float64, fuelType string) ([]*domain.FuelConsumption, error) {
+	if len(fuelMix) == 0 {
+		fuel, err := fs.emissions.ResolveFuelType(fuelType)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.FuelConsumption{{FuelType: fuel, ConsumtionInMetricTons: total}}, nil
+	}
+
+	sum := 0.0
+	fuels := make([]*domain.FuelConsumption, 0, len(fuelMix))
+	for fuel, share := range fuelMix {
+		resolved, err := fs.emissions.ResolveFuelType(fuel)
+		if err != nil {
+			return nil, err
+		}
+		sum += share
+		fuels = append(fuels, &domain.FuelConsumption{FuelType: resolved, ConsumtionInMetricTons: total * share})
+	}
+	if math.Abs(sum-1) > fuelMixTolerance {
+		return nil, fmt.Errorf("fuel mix shares must add up to 1, got %g", sum)
+	}
+	sort.Slice(fuels, func(i, j int) bool { return fuels[i].FuelType < fuels[j].FuelType })
+
+	return fuels, nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// weatherLayer holds all fuel map rows with the same beaufort, sorted by speed
+type weatherLayer struct {
+	weather float64
+	rows    []*domain.FuelMap
+}
+
+// fuelSurface is a fuel map of a single draught organised in weather layers.
+// Layers are sorted by beaufort so lookups can find the bracketing rows on both axes.
+// The fitted curve is used to extrapolate outside of the table, it is fitted on first use
+// as fitting is expensive and most lookups stay within the table.
+type fuelSurface struct {
+	draught float64
+	layers  []*weatherLayer
+	rows    []*domain.FuelMap
+	fit     sync.Once
+	fitted  *domain.ConsumptionCurve
+}
+
+// fuelVolume is a fuel map organised in draught surfaces sorted by draught.
+// Rows that only apply to a wind sector are kept in a volume of their own per sector.
+type fuelVolume struct {
+	surfaces []*fuelSurface
+	sectors  map[domain.WindSector]*fuelVolume
+}
+
+// newFuelVolume builds the volume of the rows for all wind directions and one per wind sector.
+// A table with sector rows only has no rows for all directions, the mean of the sectors is used for them
+// so legs without a wind sector (or with a sector the table has no rows for) are not charged 0 t/day.
+func newFuelVolume(fuelMap []*domain.FuelMap) *fuelVolume {
+	general := make([]*domain.FuelMap, 0, len(fuelMap))
+	bySector := make(map[domain.WindSector][]*domain.FuelMap)
+	for _, fm := range fuelMap {
+		if fm.WindSector != nil && *fm.WindSector != "" {
+			bySector[*fm.WindSector] = append(bySector[*fm.WindSector], fm)
+			continue
+		}
+		general = append(general, fm)
+	}
+	if len(general) == 0 {
+		general = sectorMeanRows(bySector)
+	}
+
+	volume := newDraughtVolume(general)
+	for sector, rows := range bySector {
+		volume.sectors[sector] = newDraughtVolume(rows)
+	}
+	return volume
+}
+
+// sectorMeanRows returns a row for all wind directions per draught, beaufort and speed
+// with the mean consumption of the sector rows of that cell
+func sectorMeanRows(bySector map[domain.WindSector][]*domain.FuelMap) []*domain.FuelMap {
+	type cell struct{ draught, weather, speed float64 }
+	sums := make(map[cell]*domain.FuelMap)
+	counts := make(map[cell]int)
+	cells := []cell{}
+	for _, sector := range []domain.WindSector{domain.WindHead, domain.WindBeam, domain.WindFollowing} {
+		for _, fm := range bySector[sector] {
+			key := cell{fm.Draught, fm.Weather, fm.Speed}
+			if _, exists := sums[key]; !exists {
+				row := *fm
+				row.WindSector = nil
+				row.Consumtion = 0
+				sums[key] = &row
+				cells = append(cells, key)
+			}
+			sums[key].Consumtion += fm.Consumtion
+			counts[key]++
+		}
+	}
+
+	rows := make([]*domain.FuelMap, 0, len(cells))
+	for _, key := range cells {
+		row := sums[key]
+		row.Consumtion /= float64(counts[key])
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// newDraughtVolume groups fuel map rows by draught and builds a surface for each of them
+func newDraughtVolume(fuelMap []*domain.FuelMap) *fuelVolume {
+	byDraught := make(map[float64][]*domain.FuelMap)
+	for _, fm := range fuelMap {
+		byDraught[fm.Draught] = append(byDraught[fm.Draught], fm)
+	}
+
+	volume := &fuelVolume{
+		surfaces: make([]*fuelSurface, 0, len(byDraught)),
+		sectors:  make(map[domain.WindSector]*fuelVolume),
+	}
+	for draught, rows := range byDraught {
+		surface := newFuelSurface(rows)
+		surface.draught = draught
+		surface.rows = rows
+		volume.surfaces = append(volume.surfaces, surface)
+	}
+	sort.Slice(volume.surfaces, func(i, j int) bool { return volume.surfaces[i].draught < volume.surfaces[j].draught })
+
+	return volume
+}
+
+// draughtLayersFor returns the draughts of the surfaces used to interpolate the given draughts, sorted
+func (fv *fuelVolume) draughtLayersFor(draughts []float64) []float64 {
+	used := make(map[float64]struct{})
+	for _, draught := range draughts {
+		if len(fv.surfaces) == 0 {
+			break
+		}
+		lower, upper := bracketSurfaces(fv.surfaces, draught)
+		used[lower.draught] = struct{}{}
+		used[upper.draught] = struct{}{}
+	}
+
+	layers := make([]float64, 0, len(used))
+	for _, surface := range fv.surfaces {
+		if _, exists := used[surface.draught]; exists {
+			layers = append(layers, surface.draught)
+		}
+	}
+	return layers
+}
+
+// curves returns the fitted curves of all surfaces in the volume
+func (fv *fuelVolume) curves() []*domain.ConsumptionCurve {
+	curves := make([]*domain.ConsumptionCurve, 0, len(fv.surfaces))
+	for _, surface := range fv.surfaces {
+		if curve := surface.curve(); curve != nil {
+			curves = append(curves, curve)
+		}
+	}
+	return curves
+}
+
+// draughtLayers returns the draughts of all surfaces in the volume
+func (fv *fuelVolume) draughtLayers() []float64 {
+	layers := make([]float64, 0, len(fv.surfaces))
+	for _, surface := range fv.surfaces {
+		layers = append(layers, surface.draught)
+	}
+	return layers
+}
+
+// consumption interpolates daily consumption between the surrounding draught surfaces (trilinear).
+// Outside of the draught range the closest surface is used. The fuel map rows used are also returned.
+func (fv *fuelVolume) consumption(draught float64, speed float64, weather float64) (float64, []*domain.FuelMap) {
+	if len(fv.surfaces) == 0 {
+		return 0, nil
+	}
+	lower, upper := bracketSurfaces(fv.surfaces, draught)
+	lowerConsumption, lowerRows := lower.consumption(speed, weather)
+	if lower == upper {
+		return lowerConsumption, lowerRows
+	}
+	upperConsumption, upperRows := upper.consumption(speed, weather)
+
+	return lerp(lower.draught, lowerConsumption, upper.draught, upperConsumption, draught), append(lowerRows, upperRows...)
+}
+
+// windConsumption returns the daily consumption for wind from the given sector.
+// The rows of the sector are used when the table has them, otherwise the weather part of the
+// consumption (above calm weather) is scaled by the factor of the sector.
+func (fv *fuelVolume) windConsumption(
+	draught float64,
+	speed float64,
+	weather float64,
+	sector domain.WindSector,
+	factors domain.DirectionalFactors,
+) (float64, []*domain.FuelMap) {
+	if sectorVolume, exists := fv.sectors[sector]; exists && len(sectorVolume.surfaces) > 0 {
+		return sectorVolume.consumption(draught, speed, weather)
+	}
+
+	consumption, rows := fv.consumption(draught, speed, weather)
+	factor := factors.Factor(sector)
+	if factor == 1 {
+		return consumption, rows
+	}
+	calm, _ := fv.consumption(draught, speed, 0)
+	return math.Max(0, calm+factor*(consumption-calm)), rows
+}
+
+// newFuelSurface groups fuel map rows by weather and sorts them for interpolation
+func newFuelSurface(fuelMap []*domain.FuelMap) *fuelSurface {
+	byWeather := make(map[float64]*weatherLayer)
+	for _, fm := range fuelMap {
+		layer, exists := byWeather[fm.Weather]
+		if !exists {
+			layer = &weatherLayer{weather: fm.Weather}
+			byWeather[fm.Weather] = layer
+		}
+		layer.rows = append(layer.rows, fm)
+	}
+
+	surface := &fuelSurface{layers: make([]*weatherLayer, 0, len(byWeather))}
+	for _, layer := range byWeather {
+		l := layer
+		sort.SliceStable(l.rows, func(i, j int) bool { return l.rows[i].Speed < l.rows[j].Speed })
+		surface.layers = append(surface.layers, l)
+	}
+	sort.Slice(surface.layers, func(i, j int) bool { return surface.layers[i].weather < surface.layers[j].weather })
+
+	return surface
+}
+
+// curve returns the consumption curve fitted on the rows of the surface, fitting it on first use
+func (fs *fuelSurface) curve() *domain.ConsumptionCurve {
+	fs.fit.Do(func() {
+		fs.fitted = fitConsumptionCurve(fs.draught, fs.rows)
+	})
+	return fs.fitted
+}
+
+// consumption interpolates daily consumption between the surrounding rows on weather and speed.
+// Outside of the table range the fitted curve is followed from the closest edge value.
+func (fs *fuelSurface) consumption(speed float64, weather float64) (float64, []*domain.FuelMap) {
+	if len(fs.layers) == 0 {
+		return 0, nil
+	}
+	lower, upper := bracketLayers(fs.layers, weather)
+	lowerConsumption, lowerRows := lower.consumption(speed, fs.curve)
+	if lower == upper {
+		if weather == lower.weather {
+			return lowerConsumption, lowerRows
+		}
+		return extrapolateWeather(fs.curve(), lower.weather, lowerConsumption, weather), lowerRows
+	}
+	upperConsumption, upperRows := upper.consumption(speed, fs.curve)
+
+	return lerp(lower.weather, lowerConsumption, upper.weather, upperConsumption, weather), append(lowerRows, upperRows...)
+}
+
+// consumption interpolates daily consumption on speed for a single weather layer.
+// Outside of the speed range the curve is followed from the edge row, curve is only called then.
+func (wl *weatherLayer) consumption(speed float64, curve func() *domain.ConsumptionCurve) (float64, []*domain.FuelMap) {
+	rows := wl.rows
+	if speed == rows[0].Speed {
+		return rows[0].Consumtion, []*domain.FuelMap{rows[0]}
+	}
+	if speed < rows[0].Speed {
+		return extrapolateSpeed(curve(), rows[0].Speed, rows[0].Consumtion, speed), []*domain.FuelMap{rows[0]}
+	}
+	last := rows[len(rows)-1]
+	if speed == last.Speed {
+		return last.Consumtion, []*domain.FuelMap{last}
+	}
+	if speed > last.Speed {
+		return extrapolateSpeed(curve(), last.Speed, last.Consumtion, speed), []*domain.FuelMap{last}
+	}
+	// first row with speed >= target, guaranteed to be at index > 0 because of the checks above
+	i := sort.Search(len(rows), func(i int) bool { return rows[i].Speed >= speed })
+	if rows[i].Speed == speed {
+		return rows[i].Consumtion, []*domain.FuelMap{rows[i]}
+	}
+
+	return lerp(rows[i-1].Speed, rows[i-1].Consumtion, rows[i].Speed, rows[i].Consumtion, speed), []*domain.FuelMap{rows[i-1], rows[i]}
+}
+
+// bracketLayers returns the weather layers right below and above target.
+// When target is outside of the table both returned layers are the edge layer.
+func bracketLayers(layers []*weatherLayer, weather float64) (*weatherLayer, *weatherLayer) {
+	if weather <= layers[0].weather {
+		return layers[0], layers[0]
+	}
+	last := layers[len(layers)-1]
+	if weather >= last.weather {
+		return last, last
+	}
+	i := sort.Search(len(layers), func(i int) bool { return layers[i].weather >= weather })
+	if layers[i].weather == weather {
+		return layers[i], layers[i]
+	}
+
+	return layers[i-1], layers[i]
+}
+
+// bracketSurfaces returns the draught surfaces right below and above target.
+// When target is outside of the table both returned surfaces are the edge surface.
+func bracketSurfaces(surfaces []*fuelSurface, draught float64) (*fuelSurface, *fuelSurface) {
+	if draught <= surfaces[0].draught {
+		return surfaces[0], surfaces[0]
+	}
+	last := surfaces[len(surfaces)-1]
+	if draught >= last.draught {
+		return last, last
+	}
+	i := sort.Search(len(surfaces), func(i int) bool { return surfaces[i].draught >= draught })
+	if surfaces[i].draught == draught {
+		return surfaces[i], surfaces[i]
+	}
+
+	return surfaces[i-1], surfaces[i]
+}
+
+// lerp linearly interpolates y for x between points (x0,y0) and (x1,y1)
+func lerp(x0, y0, x1, y1, x float64) float64 {
+	if x1 == x0 {
+		return y0
+	}
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+const tolerance = 1e-9
+
+func TestBracketLayers(t *testing.T) {
+	layers := []*weatherLayer{{weather: 2}, {weather: 4}, {weather: 6}}
+	tests := []struct {
+		name                 string
+		weather              float64
+		wantLower, wantUpper float64
+	}{
+		{name: "below lowest", weather: 1, wantLower: 2, wantUpper: 2},
+		{name: "lowest", weather: 2, wantLower: 2, wantUpper: 2},
+		{name: "between", weather: 3, wantLower: 2, wantUpper: 4},
+		{name: "exact hit", weather: 4, wantLower: 4, wantUpper: 4},
+		{name: "highest", weather: 6, wantLower: 6, wantUpper: 6},
+		{name: "above highest", weather: 9, wantLower: 6, wantUpper: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper := bracketLayers(layers, tt.weather)
+			if lower.weather != tt.wantLower || upper.weather != tt.wantUpper {
+				t.Errorf("bracketLayers(%v) = %v, %v, want %v, %v", tt.weather, lower.weather, upper.weather, tt.wantLower, tt.wantUpper)
+			}
+		})
+	}
+}
+
+func TestBracketSurfaces(t *testing.T) {
+	surfaces := []*fuelSurface{{draught: 5}, {draught: 7}, {draught: 9}}
+	tests := []struct {
+		name                 string
+		draught              float64
+		wantLower, wantUpper float64
+	}{
+		{name: "below lowest", draught: 4.2, wantLower: 5, wantUpper: 5},
+		{name: "between", draught: 7.4, wantLower: 7, wantUpper: 9},
+		{name: "exact hit", draught: 7, wantLower: 7, wantUpper: 7},
+		{name: "above highest", draught: 12, wantLower: 9, wantUpper: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper := bracketSurfaces(surfaces, tt.draught)
+			if lower.draught != tt.wantLower || upper.draught != tt.wantUpper {
+				t.Errorf("bracketSurfaces(%v) = %v, %v, want %v, %v", tt.draught, lower.draught, upper.draught, tt.wantLower, tt.wantUpper)
+			}
+		})
+	}
+}
+
+func TestLerp(t *testing.T) {
+	tests := []struct {
+		name           string
+		x0, y0, x1, y1 float64
+		x              float64
+		want           float64
+	}{
+		{name: "at x0", x0: 10, y0: 20, x1: 12, y1: 30, x: 10, want: 20},
+		{name: "at x1", x0: 10, y0: 20, x1: 12, y1: 30, x: 12, want: 30},
+		{name: "midpoint", x0: 10, y0: 20, x1: 12, y1: 30, x: 11, want: 25},
+		{name: "decreasing", x0: 0, y0: 10, x1: 4, y1: 2, x: 1, want: 8},
+		{name: "same x", x0: 3, y0: 7, x1: 3, y1: 9, x: 3, want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lerp(tt.x0, tt.y0, tt.x1, tt.y1, tt.x); math.Abs(got-tt.want) > tolerance {
+				t.Errorf("lerp = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherLayerConsumption(t *testing.T) {
+	layer := &weatherLayer{weather: 3, rows: []*domain.FuelMap{
+		{Speed: 10, Consumtion: 20},
+		{Speed: 12, Consumtion: 30},
+		{Speed: 14, Consumtion: 44},
+	}}
+	cubic := &domain.ConsumptionCurve{Coefficient: 0.02, Exponent: 3}
+	tests := []struct {
+		name       string
+		speed      float64
+		curve      *domain.ConsumptionCurve
+		want       float64
+		wantSpeeds []float64
+		wantFitted bool
+	}{
+		{name: "exact hit", speed: 12, curve: cubic, want: 30, wantSpeeds: []float64{12}},
+		{name: "lowest", speed: 10, curve: cubic, want: 20, wantSpeeds: []float64{10}},
+		{name: "between", speed: 11, curve: cubic, want: 25, wantSpeeds: []float64{10, 12}},
+		{name: "below lowest follows the curve", speed: 8, curve: cubic, want: 20 + 0.02*(512-1000), wantSpeeds: []float64{10}, wantFitted: true},
+		{name: "above highest follows the curve", speed: 16, curve: cubic, want: 44 + 0.02*(4096-2744), wantSpeeds: []float64{14}, wantFitted: true},
+		{name: "above highest without curve", speed: 16, want: 44, wantSpeeds: []float64{14}, wantFitted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fitted := false
+			curve := func() *domain.ConsumptionCurve {
+				fitted = true
+				return tt.curve
+			}
+			got, rows := layer.consumption(tt.speed, curve)
+			if math.Abs(got-tt.want) > tolerance {
+				t.Errorf("consumption(%v) = %v, want %v", tt.speed, got, tt.want)
+			}
+			if len(rows) != len(tt.wantSpeeds) {
+				t.Fatalf("consumption(%v) used %d rows, want %d", tt.speed, len(rows), len(tt.wantSpeeds))
+			}
+			for i, row := range rows {
+				if row.Speed != tt.wantSpeeds[i] {
+					t.Errorf("row %d has speed %v, want %v", i, row.Speed, tt.wantSpeeds[i])
+				}
+			}
+			if fitted != tt.wantFitted {
+				t.Errorf("curve used = %v, want %v", fitted, tt.wantFitted)
+			}
+		})
+	}
+}
+
+func TestFuelVolumeConsumption(t *testing.T) {
+	rows := []*domain.FuelMap{}
+	for _, draught := range []float64{8, 10} {
+		for _, weather := range []float64{0, 4} {
+			for _, speed := range []float64{10, 12} {
+				rows = append(rows, &domain.FuelMap{
+					Draught:    draught,
+					Weather:    weather,
+					Speed:      speed,
+					Consumtion: draught + weather + speed,
+				})
+			}
+		}
+	}
+	volume := newFuelVolume(rows)
+	tests := []struct {
+		name                    string
+		draught, speed, weather float64
+		want                    float64
+		wantRows                int
+	}{
+		{name: "table row", draught: 8, speed: 10, weather: 0, want: 18, wantRows: 1},
+		{name: "trilinear", draught: 9, speed: 11, weather: 2, want: 22, wantRows: 8},
+		{name: "below lowest draught", draught: 6, speed: 12, weather: 4, want: 24, wantRows: 1},
+		{name: "above highest draught", draught: 11, speed: 11, weather: 0, want: 21, wantRows: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, used := volume.consumption(tt.draught, tt.speed, tt.weather)
+			if math.Abs(got-tt.want) > tolerance {
+				t.Errorf("consumption = %v, want %v", got, tt.want)
+			}
+			if len(used) != tt.wantRows {
+				t.Errorf("consumption used %d rows, want %d", len(used), tt.wantRows)
+			}
+		})
+	}
+}
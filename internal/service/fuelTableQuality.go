@@ -0,0 +1,312 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+const (
+	// defaultOutlierThreshold is the robust z-score of the residual to the fitted curve above which a row is an outlier
+	defaultOutlierThreshold = 5.0
+	// minOutlierShare is the share of the predicted consumption a residual has to exceed to be an outlier,
+	// so tables fitted closely by their curves do not report rows off by a fraction of a tonne
+	minOutlierShare = 0.1
+	// minOutlierRows is the number of rows a layer needs before its residuals are trusted to find outliers
+	minOutlierRows = 8
+	// gapStepFactor is how many times the median step between consecutive values of a layer makes a gap
+	gapStepFactor = 1.5
+)
+
+// fuelTableCell is the position of a row in the fuel table, a table holds a single row per cell
+type fuelTableCell struct {
+	draught, speed, weather float64
+	sector                  domain.WindSector
+}
+
+// fuelTableLayer is a weather layer of a draught of the fuel table, rows with a wind sector have their own layers
+type fuelTableLayer struct {
+	draught, weather float64
+	sector           domain.WindSector
+	rows             []*domain.FuelMap
+}
+
+// describe names the draught, beaufort and wind sector of a layer in issue messages
+func (l *fuelTableLayer) describe() string {
+	return fmt.Sprintf("draught %g, beaufort %g%s", l.draught, l.weather, sectorSuffix(l.sector))
+}
+
+// sectorSuffix names the wind sector of fuel table rows in issue messages, empty for rows of all directions
+func sectorSuffix(sector domain.WindSector) string {
+	if sector == "" {
+		return ""
+	}
+	return fmt.Sprintf(", %s wind", sector)
+}
+
+// fuelTableAssessment holds the rows of a fuel table being assessed with their indexes in the table
+type fuelTableAssessment struct {
+	index  map[*domain.FuelMap]int
+	issues []domain.FuelTableIssue
+}
+
+// assessFuelTable reports the data quality issues of the rows of a fuel table: duplicate cells,
+// consumption dropping as speed grows, gaps between the speeds or beauforts of a draught and rows
+// far from the consumption curve fitted for their layer. Issues reference rows by their index in rows.
+func (fs *fuelTableService) assessFuelTable(rows []*domain.FuelMap) []domain.FuelTableIssue {
+	assessment := &fuelTableAssessment{
+		index:  make(map[*domain.FuelMap]int, len(rows)),
+		issues: []domain.FuelTableIssue{},
+	}
+	for i, row := range rows {
+		assessment.index[row] = i
+	}
+
+	assessment.checkDuplicates(rows)
+	layers := fuelTableLayers(rows)
+	assessment.checkMonotonic(layers)
+	assessment.checkSpeedGaps(layers)
+	assessment.checkBeaufortGaps(layers)
+	assessment.checkOutliers(layers, fs.outlierThreshold())
+
+	return assessment.issues
+}
+
+// outlierThreshold resolves the robust z-score above which fuel table rows are outliers
+func (fs *fuelTableService) outlierThreshold() float64 {
+	if fs.cfg.Calculation.OutlierThreshold > 0 {
+		return fs.cfg.Calculation.OutlierThreshold
+	}
+	return defaultOutlierThreshold
+}
+
+// checkDuplicates reports rows filling the same cell, the rows are left as they are
+func (a *fuelTableAssessment) checkDuplicates(rows []*domain.FuelMap) {
+	cells := make(map[fuelTableCell][]*domain.FuelMap, len(rows))
+	order := make([]fuelTableCell, 0, len(rows))
+	for _, row := range rows {
+		cell := fuelTableCell{draught: row.Draught, speed: row.Speed, weather: row.Weather}
+		if row.WindSector != nil {
+			cell.sector = *row.WindSector
+		}
+		if _, exists := cells[cell]; !exists {
+			order = append(order, cell)
+		}
+		cells[cell] = append(cells[cell], row)
+	}
+
+	for _, cell := range order {
+		if duplicates := cells[cell]; len(duplicates) > 1 {
+			a.add(domain.IssueDuplicateCell, fmt.Sprintf(
+				"%d rows at draught %g, speed %g, beaufort %g%s",
+				len(duplicates), cell.draught, cell.speed, cell.weather, sectorSuffix(cell.sector),
+			), duplicates...)
+		}
+	}
+}
+
+// checkMonotonic reports consecutive rows of a layer whose consumption drops while speed grows
+func (a *fuelTableAssessment) checkMonotonic(layers []*fuelTableLayer) {
+	for _, layer := range layers {
+		for i := 1; i < len(layer.rows); i++ {
+			previous, row := layer.rows[i-1], layer.rows[i]
+			if row.Consumtion < previous.Consumtion {
+				a.add(domain.IssueNonMonotonic, fmt.Sprintf(
+					"consumption drops from %g to %g between speed %g and %g at %s",
+					previous.Consumtion, row.Consumtion, previous.Speed, row.Speed, layer.describe(),
+				), previous, row)
+			}
+		}
+	}
+}
+
+// checkSpeedGaps reports consecutive rows of a layer further apart in speed than the usual step of the layer
+func (a *fuelTableAssessment) checkSpeedGaps(layers []*fuelTableLayer) {
+	for _, layer := range layers {
+		speeds := make([]float64, 0, len(layer.rows))
+		for _, row := range layer.rows {
+			speeds = append(speeds, row.Speed)
+		}
+		for _, i := range gaps(speeds) {
+			previous, row := layer.rows[i-1], layer.rows[i]
+			a.add(domain.IssueSpeedGap, fmt.Sprintf(
+				"no rows between speed %g and %g at %s", previous.Speed, row.Speed, layer.describe(),
+			), previous, row)
+		}
+	}
+}
+
+// checkBeaufortGaps reports consecutive layers of a draught further apart in beaufort than the usual step of the draught
+func (a *fuelTableAssessment) checkBeaufortGaps(layers []*fuelTableLayer) {
+	for start := 0; start < len(layers); {
+		end := start + 1
+		for end < len(layers) && layers[end].draught == layers[start].draught && layers[end].sector == layers[start].sector {
+			end++
+		}
+		draughtLayers := layers[start:end]
+		weathers := make([]float64, 0, len(draughtLayers))
+		for _, layer := range draughtLayers {
+			weathers = append(weathers, layer.weather)
+		}
+		for _, i := range gaps(weathers) {
+			a.add(domain.IssueBeaufortGap, fmt.Sprintf(
+				"no rows between beaufort %g and %g at draught %g%s",
+				weathers[i-1], weathers[i], draughtLayers[i].draught, sectorSuffix(draughtLayers[i].sector),
+			))
+		}
+		start = end
+	}
+}
+
+// checkOutliers fits a consumption curve on the speeds of every layer and reports the rows whose residual is
+// further from the median residual than threshold times the robust standard deviation of the residuals,
+// and off the curve by more than minOutlierShare of the prediction. Layers are fitted on their own as the
+// weather changes the shape of the curve. The curve is fitted again without the rows found on the first fit,
+// so gross errors do not pull it away from the rows next to them.
+func (a *fuelTableAssessment) checkOutliers(layers []*fuelTableLayer, threshold float64) {
+	for _, layer := range layers {
+		fit := newOutlierFit(layer.draught, layer.rows)
+		if fit == nil {
+			continue
+		}
+		inliers := make([]*domain.FuelMap, 0, len(layer.rows))
+		for _, row := range layer.rows {
+			if _, outlier := fit.outlier(row, threshold); !outlier {
+				inliers = append(inliers, row)
+			}
+		}
+		if len(inliers) < len(layer.rows) {
+			if refit := newOutlierFit(layer.draught, inliers); refit != nil {
+				fit = refit
+			}
+		}
+
+		for _, row := range layer.rows {
+			if residual, outlier := fit.outlier(row, threshold); outlier {
+				a.add(domain.IssueOutlier, fmt.Sprintf(
+					"consumption %g at speed %g is %.2f off the curve fitted at %s",
+					row.Consumtion, row.Speed, residual, layer.describe(),
+				), row)
+			}
+		}
+	}
+}
+
+// outlierFit is a curve fitted on the rows of a layer with the spread of their residuals
+type outlierFit struct {
+	curve *domain.ConsumptionCurve
+	// center is the median residual and sigma the robust standard deviation of the residuals
+	center, sigma float64
+}
+
+// newOutlierFit fits the curve of the rows, nil when there are too few rows or the residuals do not spread
+func newOutlierFit(draught float64, rows []*domain.FuelMap) *outlierFit {
+	curve := fitConsumptionCurve(draught, rows)
+	if curve == nil || curve.Rows < minOutlierRows {
+		return nil
+	}
+	residuals := make([]float64, 0, curve.Rows)
+	for _, row := range rows {
+		if row.Speed > 0 {
+			residuals = append(residuals, row.Consumtion-curve.Consumption(row.Speed, row.Weather))
+		}
+	}
+	center := median(residuals)
+	deviations := make([]float64, 0, len(residuals))
+	for _, r := range residuals {
+		deviations = append(deviations, math.Abs(r-center))
+	}
+	// 1.4826 scales the median absolute deviation to the standard deviation of normally distributed residuals
+	sigma := 1.4826 * median(deviations)
+	if sigma == 0 {
+		return nil
+	}
+	return &outlierFit{curve: curve, center: center, sigma: sigma}
+}
+
+// outlier returns the residual of the row to the curve and tells if the row is an outlier, rows at speed 0 never are
+func (f *outlierFit) outlier(row *domain.FuelMap, threshold float64) (float64, bool) {
+	if row.Speed <= 0 {
+		return 0, false
+	}
+	prediction := f.curve.Consumption(row.Speed, row.Weather)
+	residual := row.Consumtion - prediction
+	return residual, math.Abs(residual-f.center) > threshold*f.sigma && math.Abs(residual) > minOutlierShare*prediction
+}
+
+// add appends an issue referencing the given rows by their index in the table
+func (a *fuelTableAssessment) add(code domain.FuelTableIssueCode, message string, rows ...*domain.FuelMap) {
+	indexes := make([]int, 0, len(rows))
+	for _, row := range rows {
+		indexes = append(indexes, a.index[row])
+	}
+	a.issues = append(a.issues, domain.FuelTableIssue{Code: code, Message: message, Rows: indexes})
+}
+
+// fuelTableLayers groups the rows in layers sorted by wind sector, draught and beaufort, rows of a layer sorted by speed
+func fuelTableLayers(rows []*domain.FuelMap) []*fuelTableLayer {
+	byLayer := make(map[fuelTableCell]*fuelTableLayer)
+	layers := []*fuelTableLayer{}
+	for _, row := range rows {
+		key := fuelTableCell{draught: row.Draught, weather: row.Weather}
+		if row.WindSector != nil {
+			key.sector = *row.WindSector
+		}
+		layer, exists := byLayer[key]
+		if !exists {
+			layer = &fuelTableLayer{draught: key.draught, weather: key.weather, sector: key.sector}
+			byLayer[key] = layer
+			layers = append(layers, layer)
+		}
+		layer.rows = append(layer.rows, row)
+	}
+
+	sort.Slice(layers, func(i, j int) bool {
+		a, b := layers[i], layers[j]
+		if a.sector != b.sector {
+			return a.sector < b.sector
+		}
+		if a.draught != b.draught {
+			return a.draught < b.draught
+		}
+		return a.weather < b.weather
+	})
+	for _, layer := range layers {
+		sort.SliceStable(layer.rows, func(i, j int) bool { return layer.rows[i].Speed < layer.rows[j].Speed })
+	}
+	return layers
+}
+
+// gaps returns the indexes of the sorted values further from the previous one than gapStepFactor times the median step
+func gaps(values []float64) []int {
+	if len(values) < 3 {
+		return nil
+	}
+	steps := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		steps = append(steps, values[i]-values[i-1])
+	}
+	limit := gapStepFactor * median(steps)
+
+	indexes := []int{}
+	for i, step := range steps {
+		// values are read from csv, the tolerance keeps steps like 7.1 - 7.0 from being a gap
+		if step > limit+1e-9 {
+			indexes = append(indexes, i+1)
+		}
+	}
+	return indexes
+}
+
+// median returns the median of the values, the values are not modified
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/repository/db"
+)
+
+// FuelTableService is an interface for managing vessel fuel tables
+type FuelTableService interface {
+	ListFuelTables(ctx context.Context) ([]*domain.FuelTableSummary, error)
+	GetFuelTable(ctx context.Context, imo int, at time.Time) ([]*domain.FuelMap, error)
+	GetFuelTableVersions(ctx context.Context, imo int) ([]*domain.FuelTableSummary, error)
+	GetFuelTableQuality(ctx context.Context, imo int, at time.Time) (*domain.FuelTableQuality, error)
+	ReplaceFuelTable(ctx context.Context, imo int, validFrom *time.Time, rows []*domain.FuelMap) (*domain.FuelTableQuality, error)
+	DeleteFuelTable(ctx context.Context, imo int) error
+}
+
+// fuelTableService is a concrete implementation of the above interface
+type fuelTableService struct {
+	cfg          *config.Config
+	fuelRepo     db.VesselRepo
+	registryRepo db.RegistryRepo
+	logger       logger.Logger
+}
+
+// NewFuelTableService makes a new fuel table service provided the external dependencies
+func NewFuelTableService(cfg *config.Config, fr db.VesselRepo, rr db.RegistryRepo, log logger.Logger) FuelTableService {
+	return &fuelTableService{
+		cfg:          cfg,
+		fuelRepo:     fr,
+		registryRepo: rr,
+		logger:       log,
+	}
+}
+
+// ListFuelTables returns the summary of the fuel table version in effect of every vessel
+func (fs *fuelTableService) ListFuelTables(ctx context.Context) ([]*domain.FuelTableSummary, error) {
+	return fs.fuelRepo.ListFuelMaps(ctx)
+}
+
+// GetFuelTable returns all rows of the vessel fuel table version in effect at the given time
+func (fs *fuelTableService) GetFuelTable(ctx context.Context, imo int, at time.Time) ([]*domain.FuelMap, error) {
+	operation := errors.Op("service.fuelTableService.GetFuelTable")
+
+	rows, err := fs.fuelRepo.GetFuelMap(ctx, imo, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+	}
+	return rows, nil
+}
+
+// GetFuelTableVersions returns the summary of every fuel table version of the vessel, oldest first
+func (fs *fuelTableService) GetFuelTableVersions(ctx context.Context, imo int) ([]*domain.FuelTableSummary, error) {
+	operation := errors.Op("service.fuelTableService.GetFuelTableVersions")
+
+	versions, err := fs.fuelRepo.GetFuelMapVersions(ctx, imo)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+	}
+	return versions, nil
+}
+
+// GetFuelTableQuality returns the data quality report of the vessel fuel table version in effect at the given time,
+// issues reference rows by their index in the fuel table as downloaded
+func (fs *fuelTableService) GetFuelTableQuality(ctx context.Context, imo int, at time.Time) (*domain.FuelTableQuality, error) {
+	rows, err := fs.GetFuelTable(ctx, imo, at)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &domain.FuelTableVersion{ID: rows[0].VersionID, IMO: imo, ValidFrom: rows[0].ValidFrom, ValidTo: rows[0].ValidTo}
+	issues := fs.assessFuelTable(rows)
+	return &domain.FuelTableQuality{FuelTableSummary: *domain.SummariseFuelTable(version, rows), Issues: issues}, nil
+}
+
+// ReplaceFuelTable validates the rows and saves them as a fuel table version of the vessel.
+// Rows are saved as uploaded and the data quality report of the rows is returned, issues reference rows
+// by their index in the uploaded table. Rows filling the same cell reject the upload as the table is ambiguous.
+// Without validFrom the rows replace the latest version. With validFrom a new version starts then and the
+// latest version ends, unless the latest version starts at the same time and its rows are replaced.
+// Versions can only be added after the start of the latest one, and only for registered vessels.
+func (fs *fuelTableService) ReplaceFuelTable(
+	ctx context.Context,
+	imo int,
+	validFrom *time.Time,
+	rows []*domain.FuelMap,
+) (*domain.FuelTableQuality, error) {
+	operation := errors.Op("service.fuelTableService.ReplaceFuelTable")
+
+	if err := domain.ValidateFuelTable(imo, rows); err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	issues := fs.assessFuelTable(rows)
+	if duplicates := duplicateCells(issues); duplicates != "" {
+		return nil, errors.E(operation, errors.KindBadInput, "fuel table has rows of the same cell: "+duplicates)
+	}
+
+	if _, err := fs.registryRepo.GetVessel(ctx, imo); err != nil {
+		return nil, err
+	}
+	versions, err := fs.fuelRepo.GetFuelMapVersions(ctx, imo)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &domain.FuelTableVersion{IMO: imo, ValidFrom: validFrom}
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1].FuelTableVersion
+		switch {
+		case validFrom == nil:
+			version = &latest
+		case latest.ValidFrom != nil && validFrom.Equal(*latest.ValidFrom):
+			version = &latest
+		case latest.ValidFrom != nil && validFrom.Before(*latest.ValidFrom):
+			return nil, errors.E(operation, errors.KindBadInput, fmt.Sprintf(
+				"validFrom must be after the start of the latest fuel table version %s", latest.ValidFrom.Format(time.RFC3339),
+			))
+		}
+	}
+	if version.ID == uuid.Nil && validFrom != nil {
+		for _, existing := range versions {
+			if existing.ValidTo != nil && validFrom.Before(*existing.ValidTo) {
+				return nil, errors.E(operation, errors.KindBadInput, fmt.Sprintf(
+					"validFrom must be after the end of the fuel table version ending %s", existing.ValidTo.Format(time.RFC3339),
+				))
+			}
+		}
+	}
+
+	saved, err := fs.fuelRepo.SaveFuelMapVersion(ctx, version, rows)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.FuelTableQuality{FuelTableSummary: *domain.SummariseFuelTable(saved, rows), Issues: issues}, nil
+}
+
+// duplicateCells describes the duplicate cell issues with their rows, empty when there are none
+func duplicateCells(issues []domain.FuelTableIssue) string {
+	described := []string{}
+	for _, issue := range issues {
+		if issue.Code != domain.IssueDuplicateCell {
+			continue
+		}
+		rows := make([]string, 0, len(issue.Rows))
+		for _, row := range issue.Rows {
+			rows = append(rows, strconv.Itoa(row))
+		}
+		described = append(described, fmt.Sprintf("%s (rows %s)", issue.Message, strings.Join(rows, ", ")))
+	}
+	return strings.Join(described, "; ")
+}
+
+// DeleteFuelTable deletes all versions of the vessel fuel table
+func (fs *fuelTableService) DeleteFuelTable(ctx context.Context, imo int) error {
+	operation := errors.Op("service.fuelTableService.DeleteFuelTable")
+
+	deleted, err := fs.fuelRepo.DeleteFuelMap(ctx, imo)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+// fuelVersion is the volume of a fuel table version with the period it is in effect
+type fuelVersion struct {
+	version domain.FuelTableVersion
+	volume  *fuelVolume
+}
+
+// fuelVersions are the volumes of the fuel table versions loaded for a vessel, oldest first.
+// Legs are charged from the version in effect at their start.
+type fuelVersions []*fuelVersion
+
+// newFuelVersions groups fuel map rows by version and builds a volume for each of them.
+// Without rows a single empty version in effect since ever is returned.
+func newFuelVersions(fuelMap []*domain.FuelMap) fuelVersions {
+	byVersion := make(map[uuid.UUID][]*domain.FuelMap)
+	for _, fm := range fuelMap {
+		byVersion[fm.VersionID] = append(byVersion[fm.VersionID], fm)
+	}
+	if len(byVersion) == 0 {
+		return fuelVersions{{volume: newFuelVolume(nil)}}
+	}
+
+	versions := make(fuelVersions, 0, len(byVersion))
+	for id, rows := range byVersion {
+		versions = append(versions, &fuelVersion{
+			version: domain.FuelTableVersion{
+				ID:        id,
+				IMO:       rows[0].VesselId,
+				ValidFrom: rows[0].ValidFrom,
+				ValidTo:   rows[0].ValidTo,
+			},
+			volume: newFuelVolume(rows),
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, b := versions[i].version.ValidFrom, versions[j].version.ValidFrom
+		return a == nil && b != nil || a != nil && b != nil && a.Before(*b)
+	})
+	return versions
+}
+
+// at returns the volume of the version in effect at t. When no loaded version is in effect
+// the closest one is returned and inEffect is false.
+func (fvs fuelVersions) at(t time.Time) (volume *fuelVolume, inEffect bool) {
+	closest := fvs[0]
+	for _, fv := range fvs {
+		if fv.version.InEffect(t) {
+			return fv.volume, true
+		}
+		if fv.version.DistanceTo(t) < closest.version.DistanceTo(t) {
+			closest = fv
+		}
+	}
+	return closest.volume, false
+}
+
+// forLeg returns the volume of the version in effect at the start of the leg
+func (fvs fuelVersions) forLeg(ptp *domain.PointToPoint) *fuelVolume {
+	volume, _ := fvs.at(ptp.Source.Date)
+	return volume
+}
+
+// draughtLayersFor returns the draughts of the surfaces used to interpolate the legs, sorted
+func (fvs fuelVersions) draughtLayersFor(legs []*domain.PointToPoint) []float64 {
+	used := make(map[float64]struct{})
+	for _, ptp := range legs {
+		for _, layer := range fvs.forLeg(ptp).draughtLayersFor([]float64{ptp.Draught}) {
+			used[layer] = struct{}{}
+		}
+	}
+
+	layers := make([]float64, 0, len(used))
+	for layer := range used {
+		layers = append(layers, layer)
+	}
+	sort.Float64s(layers)
+	return layers
+}
+
+// speeds returns all distinct speeds of the versions sorted ascending
+func (fvs fuelVersions) speeds() []float64 {
+	unique := make(map[float64]struct{})
+	for _, fv := range fvs {
+		for _, speed := range fv.volume.speeds() {
+			unique[speed] = struct{}{}
+		}
+	}
+
+	speeds := make([]float64, 0, len(unique))
+	for speed := range unique {
+		speeds = append(speeds, speed)
+	}
+	sort.Float64s(speeds)
+
+	return speeds
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kkr2/vessels/internal/domain"
+)
+
+const (
+	// speedConfidenceScale, weatherConfidenceScale and draughtConfidenceScale are the distances from the
+	// fuel map rows used at which leg confidence drops to 1/e
+	speedConfidenceScale   = 2.0
+	weatherConfidenceScale = 2.0
+	draughtConfidenceScale = 2.0
+	// missingWeatherConfidence multiplies the confidence of legs with missing weather
+	missingWeatherConfidence = 0.5
+	// defaultMaxGapInHours and defaultDraughtToleranceInMeters are used when not configured
+	defaultMaxGapInHours            = 6
+	defaultDraughtToleranceInMeters = 1
+)
+
+// tableRange is the speed and weather range covered by a fuel volume
+type tableRange struct {
+	minSpeed, maxSpeed     float64
+	minWeather, maxWeather float64
+}
+
+// union returns the range covering both ranges
+func (tr tableRange) union(other tableRange) tableRange {
+	return tableRange{
+		minSpeed: math.Min(tr.minSpeed, other.minSpeed), maxSpeed: math.Max(tr.maxSpeed, other.maxSpeed),
+		minWeather: math.Min(tr.minWeather, other.minWeather), maxWeather: math.Max(tr.maxWeather, other.maxWeather),
+	}
+}
+
+// tableRange returns the speed and weather range of all rows in the volume
+func (fv *fuelVolume) tableRange() tableRange {
+	tr := tableRange{
+		minSpeed: math.Inf(1), maxSpeed: math.Inf(-1),
+		minWeather: math.Inf(1), maxWeather: math.Inf(-1),
+	}
+	for _, surface := range fv.surfaces {
+		for _, layer := range surface.layers {
+			tr.minWeather = math.Min(tr.minWeather, layer.weather)
+			tr.maxWeather = math.Max(tr.maxWeather, layer.weather)
+			for _, row := range layer.rows {
+				tr.minSpeed = math.Min(tr.minSpeed, row.Speed)
+				tr.maxSpeed = math.Max(tr.maxSpeed, row.Speed)
+			}
+		}
+	}
+	return tr
+}
+
+// assessQuality sets the confidence of every leg and of the route and adds warnings about
+// inputs the result is less reliable for. Legs are checked against the fuel table version they were charged from.
+func (vs *vesselService) assessQuality(versions fuelVersions, route *domain.RouteConsumption) {
+	warnings := vs.draughtWarnings(versions, route.Legs)
+
+	ranges := make(map[*fuelVolume]tableRange)
+	var tr tableRange
+	maxGapInHours := vs.maxGapInHours()
+	var speedLegs, weatherLegs, missingLegs, gapLegs, outOfEffectLegs []int
+	for i, ptp := range route.Legs {
+		ptp.Confidence = legConfidence(ptp)
+		volume, inEffect := versions.at(ptp.Source.Date)
+		if !inEffect {
+			outOfEffectLegs = append(outOfEffectLegs, i)
+		}
+		legRange, exists := ranges[volume]
+		if !exists {
+			legRange = volume.tableRange()
+			ranges[volume] = legRange
+			if len(ranges) == 1 {
+				tr = legRange
+			}
+			tr = tr.union(legRange)
+		}
+		if ptp.WeatherMissing {
+			missingLegs = append(missingLegs, i)
+		}
+		if ptp.TimeDiffInMins > maxGapInHours*60 {
+			gapLegs = append(gapLegs, i)
+		}
+		if ptp.State != domain.LegSailing {
+			continue
+		}
+		if ptp.AvgSpeedInKnot < legRange.minSpeed || ptp.AvgSpeedInKnot > legRange.maxSpeed {
+			speedLegs = append(speedLegs, i)
+		}
+		if ptp.AvgWeatherInBeaufort < legRange.minWeather || ptp.AvgWeatherInBeaufort > legRange.maxWeather {
+			weatherLegs = append(weatherLegs, i)
+		}
+	}
+
+	if len(speedLegs) > 0 {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.WarningSpeedOutOfTable,
+			Message: fmt.Sprintf("%d legs sailed outside of the fuel table speeds %.1f-%.1f kn", len(speedLegs), tr.minSpeed, tr.maxSpeed),
+			Legs:    speedLegs,
+		})
+	}
+	if len(weatherLegs) > 0 {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.WarningWeatherOutOfTable,
+			Message: fmt.Sprintf("%d legs sailed outside of the fuel table beaufort %.1f-%.1f", len(weatherLegs), tr.minWeather, tr.maxWeather),
+			Legs:    weatherLegs,
+		})
+	}
+	if len(missingLegs) > 0 {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.WarningMissingWeather,
+			Message: fmt.Sprintf("%d legs have hourly weather samples the weather client failed for", len(missingLegs)),
+			Legs:    missingLegs,
+		})
+	}
+	if len(outOfEffectLegs) > 0 {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.WarningNoTableInEffect,
+			Message: fmt.Sprintf("%d legs were sailed when no fuel table version was in effect, the closest version was used", len(outOfEffectLegs)),
+			Legs:    outOfEffectLegs,
+		})
+	}
+	if len(gapLegs) > 0 {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.WarningLongGap,
+			Message: fmt.Sprintf("%d legs are longer than %.0f hours", len(gapLegs), maxGapInHours),
+			Legs:    gapLegs,
+		})
+	}
+
+	route.Confidence = routeConfidence(route.Legs)
+	route.Warnings = warnings
+}
+
+// draughtWarnings returns a warning for every draught of the legs that is further than the tolerance
+// from all draughts of the fuel table version the leg was charged from
+func (vs *vesselService) draughtWarnings(versions fuelVersions, legs []*domain.PointToPoint) []domain.Warning {
+	tolerance := vs.cfg.Calculation.DraughtToleranceInMeters
+	if tolerance <= 0 {
+		tolerance = defaultDraughtToleranceInMeters
+	}
+
+	// legs far from the table are grouped by draught and closest table draught, in order of appearance
+	type farDraught struct {
+		draught, closest float64
+	}
+	var order []farDraught
+	affected := make(map[farDraught][]int)
+	for i, ptp := range legs {
+		layers := versions.forLeg(ptp).draughtLayers()
+		closest := layers[0]
+		for _, layer := range layers {
+			if math.Abs(layer-ptp.Draught) < math.Abs(closest-ptp.Draught) {
+				closest = layer
+			}
+		}
+		if math.Abs(closest-ptp.Draught) <= tolerance {
+			continue
+		}
+		key := farDraught{draught: ptp.Draught, closest: closest}
+		if _, exists := affected[key]; !exists {
+			order = append(order, key)
+		}
+		affected[key] = append(affected[key], i)
+	}
+
+	warnings := []domain.Warning{}
+	for _, key := range order {
+		warnings = append(warnings, domain.Warning{
+			Code:    domain.WarningDraughtFarFromTable,
+			Message: fmt.Sprintf("draught %.2f m is %.2f m from the closest fuel table draught %.2f m", key.draught, math.Abs(key.closest-key.draught), key.closest),
+			Legs:    affected[key],
+		})
+	}
+	return warnings
+}
+
+// maxGapInHours resolves the leg duration above which legs are warned about
+func (vs *vesselService) maxGapInHours() float64 {
+	if vs.cfg.Calculation.MaxGapInHours > 0 {
+		return vs.cfg.Calculation.MaxGapInHours
+	}
+	return defaultMaxGapInHours
+}
+
+// legConfidence scores how close the leg inputs were to the fuel map rows it was interpolated from.
+// Legs not charged from the fuel table are fully confident, sailing legs without rows are not.
+func legConfidence(ptp *domain.PointToPoint) float64 {
+	if ptp.State != domain.LegSailing {
+		return 1
+	}
+	if len(ptp.FuelMapRows) == 0 {
+		return 0
+	}
+
+	speedDistance, weatherDistance, draughtDistance := math.Inf(1), math.Inf(1), math.Inf(1)
+	for _, row := range ptp.FuelMapRows {
+		speedDistance = math.Min(speedDistance, math.Abs(row.Speed-ptp.AvgSpeedInKnot))
+		weatherDistance = math.Min(weatherDistance, math.Abs(row.Weather-ptp.AvgWeatherInBeaufort))
+		draughtDistance = math.Min(draughtDistance, math.Abs(row.Draught-ptp.Draught))
+	}
+
+	confidence := math.Exp(-(math.Pow(speedDistance/speedConfidenceScale, 2) +
+		math.Pow(weatherDistance/weatherConfidenceScale, 2) +
+		math.Pow(draughtDistance/draughtConfidenceScale, 2)))
+	if ptp.WeatherMissing {
+		confidence *= missingWeatherConfidence
+	}
+	return confidence
+}
+
+// routeConfidence averages the leg confidence weighted by leg consumption, by leg duration when nothing is consumed
+func routeConfidence(legs []*domain.PointToPoint) float64 {
+	if len(legs) == 0 {
+		return 1
+	}
+	weighted, total := 0.0, 0.0
+	for _, ptp := range legs {
+		weighted += ptp.Confidence * ptp.ExactConsumtion
+		total += ptp.ExactConsumtion
+	}
+	if total > 0 {
+		return weighted / total
+	}
+
+	weighted, total = 0.0, 0.0
+	for _, ptp := range legs {
+		weighted += ptp.Confidence * ptp.TimeDiffInMins
+		total += ptp.TimeDiffInMins
+	}
+	if total > 0 {
+		return weighted / total
+	}
+
+	sum := 0.0
+	for _, ptp := range legs {
+		sum += ptp.Confidence
+	}
+	return sum / float64(len(legs))
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/emissions"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/repository/db"
+)
+
+// RegistryService is an interface for managing vessel particulars
+type RegistryService interface {
+	GetVessel(ctx context.Context, imo int) (*domain.Vessel, error)
+	ListVessels(ctx context.Context) ([]*domain.Vessel, error)
+	SaveVessel(ctx context.Context, vessel *domain.Vessel) (*domain.Vessel, error)
+	DeleteVessel(ctx context.Context, imo int) error
+}
+
+// registryService is a concrete implementation of the above interface
+type registryService struct {
+	registryRepo db.RegistryRepo
+	emissions    *emissions.Registry
+	logger       logger.Logger
+}
+
+// NewRegistryService makes a new registry service provided the external dependencies
+func NewRegistryService(rr db.RegistryRepo, er *emissions.Registry, log logger.Logger) RegistryService {
+	return &registryService{
+		registryRepo: rr,
+		emissions:    er,
+		logger:       log,
+	}
+}
+
+// GetVessel returns the particulars of a registered vessel
+func (rs *registryService) GetVessel(ctx context.Context, imo int) (*domain.Vessel, error) {
+	return rs.registryRepo.GetVessel(ctx, imo)
+}
+
+// ListVessels returns the particulars of all registered vessels
+func (rs *registryService) ListVessels(ctx context.Context) ([]*domain.Vessel, error) {
+	return rs.registryRepo.ListVessels(ctx)
+}
+
+// SaveVessel creates or updates the particulars of a vessel.
+// The imo must have a valid check digit and the main fuel an emission factor.
+func (rs *registryService) SaveVessel(ctx context.Context, vessel *domain.Vessel) (*domain.Vessel, error) {
+	operation := errors.Op("service.registryService.SaveVessel")
+
+	if err := domain.ValidateIMO(vessel.IMO); err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	if vessel.MainFuel != nil {
+		mainFuel := domain.NormaliseFuelType(string(*vessel.MainFuel))
+		if _, err := rs.emissions.CO2Factor(mainFuel); err != nil {
+			return nil, errors.E(operation, errors.KindBadInput, err)
+		}
+		vessel.MainFuel = &mainFuel
+	}
+	return rs.registryRepo.UpsertVessel(ctx, vessel)
+}
+
+// DeleteVessel deletes the particulars of a registered vessel, its fuel table is kept
+func (rs *registryService) DeleteVessel(ctx context.Context, imo int) error {
+	operation := errors.Op("service.registryService.DeleteVessel")
+
+	deleted, err := rs.registryRepo.DeleteVessel(ctx, imo)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.E(operation, errors.KindNotFound, "vessel not registered")
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
-	"math"
-	"sort"
+	"fmt"
+	"time"
 
+	"github.com/kkr2/vessels/internal/config"
 	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/emissions"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/geodesy"
 	"github.com/kkr2/vessels/internal/logger"
 	"github.com/kkr2/vessels/internal/repository/db"
 	"github.com/kkr2/vessels/internal/repository/externalrpc"
@@ -19,112 +23,474 @@ import (
 	Given routes extract all days and get results . Client call + cache(mention).
 
 	3) Calculate aproximate consumption based on weather speed drought, refering to fuelTable
-	Problem to be solved is if result we are searching is in between rows
-	PRIORITY : Draught , Weather, Speed
+	Result we are searching is usually in between rows so it is interpolated
+	on draught, weather and speed (trilinear)
 	Optimisation: Get from db only what needed in between range
-	(closest Drought existing in db)
+	(draughts right below and above the requested drought)
 */
 
+const (
+	// defaultMaxSpeedInKnot is used to detect GPS jumps when no max speed is configured
+	defaultMaxSpeedInKnot = 40
+	// defaultDesignSpeedMargin is the design speed multiple used to detect GPS jumps when no margin is configured
+	defaultDesignSpeedMargin = 1.5
+	// defaultIdleSpeedInKnot and defaultManoeuvringSpeedInKnot classify legs when no thresholds are configured
+	defaultIdleSpeedInKnot        = 1
+	defaultManoeuvringSpeedInKnot = 3
+	// defaultFallbackBeaufort is used for legs without any weather when no fallback is configured
+	defaultFallbackBeaufort = 3
+)
+
 // VesselService is an interface for accessing vessel usecases
 type VesselService interface {
-	GetRoutesConsumtion(ctx context.Context, imo int, drought float64, vesselRoutes []*domain.Route) ([]float64, error)
+	GetRoutesConsumtion(
+		ctx context.Context,
+		imo int,
+		drought float64,
+		vesselRoutes []*domain.Route,
+		opts domain.ConsumptionOptions,
+	) ([]*domain.RouteConsumption, error)
+
+	GetBatchConsumption(ctx context.Context, entries []*domain.BatchEntry) (map[string]*domain.BatchResult, error)
+
+	GetConsumptionCurves(ctx context.Context, imo int, at time.Time) ([]*domain.ConsumptionCurve, error)
 }
 
 // vesselService is a concrete implementation of the above interface
 type vesselService struct {
+	cfg           *config.Config
 	fuelRepo      db.VesselRepo
+	registryRepo  db.RegistryRepo
 	weatherClient externalrpc.WeatherClient
+	emissions     *emissions.Registry
 	logger        logger.Logger
 }
 
 // NewVesselsService makes a new vessel service provided the external dependencies
-func NewVesselsService(fr db.VesselRepo, wc externalrpc.WeatherClient, log logger.Logger) VesselService {
+func NewVesselsService(
+	cfg *config.Config,
+	fr db.VesselRepo,
+	rr db.RegistryRepo,
+	wc externalrpc.WeatherClient,
+	er *emissions.Registry,
+	log logger.Logger,
+) VesselService {
 	return &vesselService{
+		cfg:           cfg,
 		fuelRepo:      fr,
+		registryRepo:  rr,
 		weatherClient: wc,
+		emissions:     er,
 		logger:        log,
 	}
 }
 
-// GetRoutesConsumtion provides all routes consumtion based on provided imo and drought  
-func (vs *vesselService) GetRoutesConsumtion(ctx context.Context, imo int, drought float64, vesselRoutes []*domain.Route) ([]float64, error) {
+// GetRoutesConsumtion provides all routes consumtion based on provided imo and drought.
+// Routes and data points can have their own draught, drought is used for the ones that do not.
+func (vs *vesselService) GetRoutesConsumtion(
+	ctx context.Context,
+	imo int,
+	drought float64,
+	vesselRoutes []*domain.Route,
+	opts domain.ConsumptionOptions,
+) ([]*domain.RouteConsumption, error) {
 	// TODO: Add validation
-	allRouteFuelConsumtion := []float64{}
+	operation := errors.Op("service.vesselsService.GetRoutesConsumtion")
+	allRouteFuelConsumtion := []*domain.RouteConsumption{}
+
+	vessel, err := vs.registryRepo.GetVessel(ctx, imo)
+	if err != nil {
+		return allRouteFuelConsumtion, err
+	}
+	settings, err := vs.resolveOptions(opts, vessel)
+	if err != nil {
+		return allRouteFuelConsumtion, err
+	}
 
-	fuelMaps, err := vs.fuelRepo.GetFuelMapWithClosestDrToTarget(ctx, imo, drought)
+	draughts, err := distinctDraughts(drought, vesselRoutes)
+	if err != nil {
+		return allRouteFuelConsumtion, errors.E(operation, errors.KindBadInput, err)
+	}
+	// a single fetch of the draught layers bracketing every distinct draught of the routes,
+	// in every fuel table version in effect while the routes were sailed
+	from, to := routesPeriod(vesselRoutes)
+	fuelMaps, err := vs.fuelRepo.GetFuelMapWithBracketingDrToTargets(ctx, imo, draughts, from, to)
 	if err != nil {
 		return allRouteFuelConsumtion, err
 	}
+	if len(fuelMaps) == 0 {
+		return allRouteFuelConsumtion, errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+	}
+
+	return vs.calculateRoutes(ctx, newFuelVersions(fuelMaps), vessel, drought, vesselRoutes, settings)
+}
+
+// consumptionSettings are the calculation options of a request resolved against the config
+type consumptionSettings struct {
+	opts          domain.ConsumptionOptions
+	distanceModel geodesy.DistanceModel
+	fuelType      domain.FuelType
+	co2Factor     float64
+}
+
+// resolveOptions resolves the distance model and fuel type of the request options for the vessel
+func (vs *vesselService) resolveOptions(opts domain.ConsumptionOptions, vessel *domain.Vessel) (*consumptionSettings, error) {
+	operation := errors.Op("service.vesselsService.resolveOptions")
+
+	distanceModel, err := vs.distanceModel(opts.DistanceModel)
+	if err != nil {
+		return nil, err
+	}
+
+	fuelType, err := vs.resolveFuelType(opts.FuelType, vessel)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	co2Factor, err := vs.emissions.CO2Factor(fuelType)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	return &consumptionSettings{
+		opts:          opts,
+		distanceModel: distanceModel,
+		fuelType:      fuelType,
+		co2Factor:     co2Factor,
+	}, nil
+}
+
+// resolveFuelType resolves the fuel of a calculation: the requested one, the main fuel of the vessel
+// when none is requested and the configured default for vessels without a main fuel
+func (vs *vesselService) resolveFuelType(requested string, vessel *domain.Vessel) (domain.FuelType, error) {
+	if requested == "" && vessel != nil && vessel.MainFuel != nil {
+		requested = string(*vessel.MainFuel)
+	}
+	return vs.emissions.ResolveFuelType(requested)
+}
+
+// calculateRoutes calculates the consumption of every route from the already loaded fuel table versions.
+// Auxiliary consumption and max speed come from the vessel particulars when it has them.
+func (vs *vesselService) calculateRoutes(
+	ctx context.Context,
+	versions fuelVersions,
+	vessel *domain.Vessel,
+	drought float64,
+	vesselRoutes []*domain.Route,
+	settings *consumptionSettings,
+) ([]*domain.RouteConsumption, error) {
+	operation := errors.Op("service.vesselsService.calculateRoutes")
+	allRouteFuelConsumtion := []*domain.RouteConsumption{}
+	auxiliary := vs.auxiliaryFor(vessel)
+	maxSpeed := vs.maxSpeed(settings.opts.MaxSpeedInKnot, vessel)
 
 	for _, route := range vesselRoutes {
 		r := route
-		fm := fuelMaps
-		routeConsumtion, err := vs.getRouteConsumtion(ctx, fm, r)
+		routeConsumtion, err := vs.getRouteConsumtion(ctx, versions, drought, auxiliary, settings.distanceModel, maxSpeed, r)
 		if err != nil {
 			return allRouteFuelConsumtion, err
 		}
+		vs.assessQuality(versions, routeConsumtion)
+		routeConsumtion.DraughtLayers = versions.draughtLayersFor(routeConsumtion.Legs)
+		routeConsumtion.FuelType = settings.fuelType
+		routeConsumtion.ConsumptionInCO2 = routeConsumtion.ConsumtionInMetricTons * settings.co2Factor
+		if settings.opts.Eco {
+			routeConsumtion.Eco = calculateEcoAdvisory(versions, vs.directionalFactors(), auxiliary, routeConsumtion, settings.co2Factor)
+		}
+		if settings.opts.ETSYear != 0 {
+			routeConsumtion.ETS, err = emissions.CalculateETS(routeConsumtion.Legs, settings.co2Factor, settings.opts.ETSYear)
+			if err != nil {
+				return allRouteFuelConsumtion, errors.E(operation, errors.KindBadInput, err)
+			}
+			if outOfVoyage := routeConsumtion.ETS.OutOfVoyageLegs; len(outOfVoyage) > 0 {
+				routeConsumtion.Warnings = append(routeConsumtion.Warnings, domain.Warning{
+					Code:    domain.WarningOutOfVoyage,
+					Message: fmt.Sprintf("%d legs are before the first or after the last port call and are left out of the ETS exposure", len(outOfVoyage)),
+					Legs:    outOfVoyage,
+				})
+			}
+		}
 		allRouteFuelConsumtion = append(allRouteFuelConsumtion, routeConsumtion)
 	}
 
 	return allRouteFuelConsumtion, nil
 }
 
+// GetConsumptionCurves fits a consumption curve for every draught of the vessel fuel table version in effect at the given time.
+// The curves are the ones used to extrapolate outside of the table.
+func (vs *vesselService) GetConsumptionCurves(ctx context.Context, imo int, at time.Time) ([]*domain.ConsumptionCurve, error) {
+	operation := errors.Op("service.vesselsService.GetConsumptionCurves")
+
+	if _, err := vs.registryRepo.GetVessel(ctx, imo); err != nil {
+		return nil, err
+	}
+	fuelMaps, err := vs.fuelRepo.GetFuelMap(ctx, imo, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(fuelMaps) == 0 {
+		return nil, errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+	}
+
+	return newFuelVolume(fuelMaps).curves(), nil
+}
+
 // getRouteConsumtion provides consumption for a single route
-func (vs *vesselService) getRouteConsumtion(ctx context.Context, fuelMap []*domain.FuelMap, vesselRoute *domain.Route) (float64, error) {
-	//calculate avg speed point to point
-	pointToPoints := vesselRoute.ConvertToP2P()
+func (vs *vesselService) getRouteConsumtion(
+	ctx context.Context,
+	versions fuelVersions,
+	drought float64,
+	auxiliary domain.AuxiliaryConsumption,
+	distanceModel geodesy.DistanceModel,
+	maxSpeed float64,
+	vesselRoute *domain.Route,
+) (*domain.RouteConsumption, error) {
+	operation := errors.Op("service.vesselsService.getRouteConsumtion")
+	//validate coordinates and bring longitudes into [-180, 180)
+	normalisedRoute, err := vesselRoute.Normalised()
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	//sort, merge duplicates and drop GPS jumps
+	cleanRoute, cleaning := normalisedRoute.Sanitise(maxSpeed, distanceModel)
+	if len(normalisedRoute) > 1 && len(cleanRoute) < 2 {
+		return nil, errors.E(operation, errors.KindBadInput, fmt.Sprintf(
+			"route has no legs left after removing %d datapoints, datapoints share a date or are sailed above %.1f kn",
+			len(cleaning.Removed), maxSpeed,
+		))
+	}
+	//calculate distance and avg speed point to point
+	pointToPoints := cleanRoute.ConvertToP2P(distanceModel)
+	//legs are sailed at the draught of their source, the requested drought otherwise
+	domain.AssignDraughts(pointToPoints, drought)
 	//calculate avg weather point to point based on results that we got from api
-	err := vs.calculateWeather(ctx, pointToPoints)
+	err = vs.calculateWeather(ctx, pointToPoints)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	//classify legs and interpolate consumtion , point to point based on draught , weather , speed
+	vs.calculateConsumption(ctx, versions, auxiliary, pointToPoints)
+
+	//return total consumtion together with the legs it was calculated from
+	return &domain.RouteConsumption{
+		ConsumtionInMetricTons: calculateTotalConsumtion(pointToPoints),
+		Legs:                   pointToPoints,
+		Cleaning:               cleaning,
+	}, nil
+}
+
+// maxSpeed resolves the speed above which datapoints are GPS jumps. When not given it is the design speed
+// of the vessel times the configured margin, or the config default for vessels without a design speed.
+func (vs *vesselService) maxSpeed(requested float64, vessel *domain.Vessel) float64 {
+	if requested > 0 {
+		return requested
+	}
+	if vessel.DesignSpeed != nil && *vessel.DesignSpeed > 0 {
+		return *vessel.DesignSpeed * vs.designSpeedMargin()
+	}
+	if vs.cfg.Calculation.MaxSpeedInKnot > 0 {
+		return vs.cfg.Calculation.MaxSpeedInKnot
+	}
+	return defaultMaxSpeedInKnot
+}
+
+// fallbackBeaufort resolves the beaufort of legs without any weather, so they are not charged as calm
+func (vs *vesselService) fallbackBeaufort() float64 {
+	if vs.cfg.Calculation.FallbackBeaufort > 0 {
+		return vs.cfg.Calculation.FallbackBeaufort
+	}
+	return defaultFallbackBeaufort
+}
+
+// designSpeedMargin resolves the design speed multiple above which datapoints are GPS jumps
+func (vs *vesselService) designSpeedMargin() float64 {
+	if vs.cfg.Calculation.DesignSpeedMargin > 0 {
+		return vs.cfg.Calculation.DesignSpeedMargin
+	}
+	return defaultDesignSpeedMargin
+}
+
+// weatherSampleInterval resolves the interval legs are sampled at for weather, hourly when not configured
+func (vs *vesselService) weatherSampleInterval() time.Duration {
+	if vs.cfg.Calculation.WeatherSampleInHours > 0 {
+		return time.Duration(vs.cfg.Calculation.WeatherSampleInHours * float64(time.Hour))
+	}
+	return time.Hour
+}
+
+// directionalFactors resolves the wind sector factors, factors not configured leave consumption unchanged
+func (vs *vesselService) directionalFactors() domain.DirectionalFactors {
+	factors := domain.DirectionalFactors{
+		Head:      vs.cfg.Calculation.DirectionalFactors.Head,
+		Beam:      vs.cfg.Calculation.DirectionalFactors.Beam,
+		Following: vs.cfg.Calculation.DirectionalFactors.Following,
+	}
+	if factors.Head <= 0 {
+		factors.Head = 1
 	}
-	//get most approximate consumtion , point to point based on draught , weather , speed
-	vs.calculateConsumption(ctx, fuelMap, pointToPoints)
+	if factors.Beam <= 0 {
+		factors.Beam = 1
+	}
+	if factors.Following <= 0 {
+		factors.Following = 1
+	}
+	return factors
+}
 
-	//return total consumtion
-	return calculateTotalConsumtion(pointToPoints), nil
+// stateThresholds resolves the speeds below which legs are not sailing, defaults are used when not configured
+func (vs *vesselService) stateThresholds() domain.StateThresholds {
+	thresholds := domain.StateThresholds{
+		IdleSpeedInKnot:        vs.cfg.Calculation.IdleSpeedInKnot,
+		ManoeuvringSpeedInKnot: vs.cfg.Calculation.ManoeuvringSpeedInKnot,
+	}
+	if thresholds.IdleSpeedInKnot <= 0 {
+		thresholds.IdleSpeedInKnot = defaultIdleSpeedInKnot
+	}
+	if thresholds.ManoeuvringSpeedInKnot <= 0 {
+		thresholds.ManoeuvringSpeedInKnot = defaultManoeuvringSpeedInKnot
+	}
+	return thresholds
 }
 
-// calculateWeather updates pointToPoint data structure with weather information
+// auxiliaryFor returns the config defaults overridden by the values of the vessel
+func (vs *vesselService) auxiliaryFor(vessel *domain.Vessel) domain.AuxiliaryConsumption {
+	defaults := domain.AuxiliaryConsumption{
+		Manoeuvring: vs.cfg.Calculation.AuxiliaryConsumption.Manoeuvring,
+		Anchored:    vs.cfg.Calculation.AuxiliaryConsumption.Anchored,
+		Berthed:     vs.cfg.Calculation.AuxiliaryConsumption.Berthed,
+	}
+	return defaults.WithVessel(vessel)
+}
+
+// distanceModel resolves the requested distance model, config default is used when no name is given
+func (vs *vesselService) distanceModel(name string) (geodesy.DistanceModel, error) {
+	operation := errors.Op("service.vesselsService.distanceModel")
+	if name == "" {
+		name = vs.cfg.Calculation.DistanceModel
+	}
+	if name == "" {
+		name = geodesy.VincentyModel
+	}
+	model, err := geodesy.ModelByName(name)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	return model, nil
+}
+
+// calculateWeather updates pointToPoint data structure with the weather sampled along the leg,
+// weighted by the part of the leg every sample stands for.
+// Samples the weather client fails for are marked missing instead of failing the calculation,
+// unless it fails for every sample of the route: a route calculated in fallback weather only is an error.
 func (vs *vesselService) calculateWeather(ctx context.Context, pointToPoints []*domain.PointToPoint) error {
+	operation := errors.Op("service.vesselsService.calculateWeather")
+	interval := vs.weatherSampleInterval()
+	fallback := vs.fallbackBeaufort()
+	var lastErr error
+	total, totalMissing := 0, 0
 	for _, ptp := range pointToPoints {
 		ptp := ptp
-		srcWeather, err := vs.weatherClient.GetWeatherForDay(ctx, ptp.Source.Date)
-		if err != nil {
-			return err
+		samples := ptp.WeatherSamples(interval)
+		missing := 0
+		for _, sample := range samples {
+			weather, err := vs.weatherClient.GetWeatherAt(ctx, sample.Latitude, sample.Longitude, sample.Time)
+			if err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				sample.Missing = true
+				lastErr = err
+				missing++
+				continue
+			}
+			sample.Weather = weather
 		}
-		dstWeather, err := vs.weatherClient.GetWeatherForDay(ctx, ptp.Destination.Date)
-		if err != nil {
-			return err
+		if missing > 0 {
+			vs.logger.Warnf("weather missing for %d of %d samples of leg starting %s: %v", missing, len(samples), ptp.Source.Date, lastErr)
 		}
-		ptp.AddWeatherInfo(srcWeather, dstWeather)
+		total += len(samples)
+		totalMissing += missing
+		ptp.AddWeatherInfo(samples, fallback)
 
 	}
+	if total > 0 && totalMissing == total {
+		return errors.E(operation, errors.KindExternalRPC, lastErr)
+	}
 	return nil
 }
 
-// calculateConsumption updates pointToPoint data structure with avg fuel consumption info
-func (vs *vesselService) calculateConsumption(ctx context.Context, fuelMap []*domain.FuelMap, pointToPoints []*domain.PointToPoint) {
+// calculateConsumption updates pointToPoint data structure with its state and avg fuel consumption info.
+// Sailing legs are charged from the fuel table version in effect at their start for their wind sector,
+// the others with the auxiliary consumption of their state.
+func (vs *vesselService) calculateConsumption(
+	ctx context.Context,
+	versions fuelVersions,
+	auxiliary domain.AuxiliaryConsumption,
+	pointToPoints []*domain.PointToPoint,
+) {
+	thresholds := vs.stateThresholds()
+	factors := vs.directionalFactors()
 	for _, ptp := range pointToPoints {
 		ptp := ptp
-		avgConsumption := getClosestConsumtion(fuelMap, ptp.AvgSpeedInKnot, ptp.AvgWeatherInBeaufort)
+		ptp.ClassifyState(thresholds)
+		if ptp.State != domain.LegSailing {
+			ptp.AddConsumtion(auxiliary.ForState(ptp.State), nil)
+			continue
+		}
+		avgConsumption, fuelMapRows := versions.forLeg(ptp).windConsumption(ptp.Draught, ptp.AvgSpeedInKnot, ptp.AvgWeatherInBeaufort, ptp.WindSector, factors)
 
-		ptp.AddConsumtion(avgConsumption)
+		ptp.AddConsumtion(avgConsumption, fuelMapRows)
 
 	}
 }
 
-// getClosestConsumtion gets closest sum from fuelmap provided by db, based on weather and speed (since closes to drought is provided by db)
-func getClosestConsumtion(fuelMap []*domain.FuelMap, speed float64, weather float64) float64 {
-	sort.Slice(fuelMap, func(i, j int) bool {
-		if math.Abs(fuelMap[i].Weather-weather) != math.Abs(fuelMap[j].Weather-weather) {
-			return math.Abs(fuelMap[i].Weather-weather) < math.Abs(fuelMap[j].Weather-weather)
+// distinctDraughts returns the drought and every distinct draught given on the data points of the routes
+func distinctDraughts(drought float64, vesselRoutes []*domain.Route) ([]float64, error) {
+	seen := map[float64]struct{}{drought: {}}
+	draughts := []float64{drought}
+	for _, route := range vesselRoutes {
+		for i, point := range *route {
+			if point.Draught == nil {
+				continue
+			}
+			if *point.Draught <= 0 {
+				return nil, fmt.Errorf("datapoint %d: draught must be positive", i)
+			}
+			if _, exists := seen[*point.Draught]; !exists {
+				seen[*point.Draught] = struct{}{}
+				draughts = append(draughts, *point.Draught)
+			}
 		}
-		return math.Abs(fuelMap[i].Speed-speed) < math.Abs(fuelMap[j].Speed-speed)
+	}
+	return draughts, nil
+}
 
-	})
+// routesPeriod returns the first and last timestamp of the routes
+func routesPeriod(vesselRoutes []*domain.Route) (time.Time, time.Time) {
+	var from, to time.Time
+	for _, route := range vesselRoutes {
+		for _, point := range *route {
+			if from.IsZero() || point.Date.Before(from) {
+				from = point.Date
+			}
+			if to.IsZero() || point.Date.After(to) {
+				to = point.Date
+			}
+		}
+	}
+	return from, to
+}
 
-	return fuelMap[0].Consumtion
+// legDraughts returns the distinct draughts the legs are sailed at
+func legDraughts(pointToPoints []*domain.PointToPoint) []float64 {
+	seen := map[float64]struct{}{}
+	draughts := []float64{}
+	for _, ptp := range pointToPoints {
+		if _, exists := seen[ptp.Draught]; !exists {
+			seen[ptp.Draught] = struct{}{}
+			draughts = append(draughts, ptp.Draught)
+		}
+	}
+	return draughts
 }
 
 // calculateTotalConsumtion is a helper function to add all exact consumtion from point to point data
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kkr2/vessels/internal/config"
+	"github.com/kkr2/vessels/internal/domain"
+	"github.com/kkr2/vessels/internal/emissions"
+	"github.com/kkr2/vessels/internal/errors"
+	"github.com/kkr2/vessels/internal/geodesy"
+	"github.com/kkr2/vessels/internal/logger"
+	"github.com/kkr2/vessels/internal/repository/db"
+	"github.com/kkr2/vessels/internal/repository/externalrpc"
+)
+
+const (
+	// speedStep is the resolution in kn of the speeds considered by the optimiser
+	speedStep = 0.1
+	// maxScheduleIterations limits how many times weather is refreshed for the optimised schedule
+	maxScheduleIterations = 5
+	// lambdaSearchIterations is the number of bisections used to find the time cost meeting the arrival
+	lambdaSearchIterations = 60
+)
+
+// VoyageService is an interface for planned voyage usecases
+type VoyageService interface {
+	OptimiseSpeed(
+		ctx context.Context,
+		imo int,
+		drought float64,
+		departure time.Time,
+		arrival time.Time,
+		waypoints []domain.Waypoint,
+		opts domain.ConsumptionOptions,
+	) (*domain.SpeedProfile, error)
+
+	PlanVoyage(
+		ctx context.Context,
+		imo int,
+		drought float64,
+		departure time.Time,
+		waypoints []domain.Waypoint,
+		speeds []float64,
+		opts domain.ConsumptionOptions,
+	) (*domain.PlannedVoyage, error)
+}
+
+// voyageService is a concrete implementation of the above interface.
+// It reuses the consumption pipeline of vesselService for the planned legs.
+type voyageService struct {
+	*vesselService
+}
+
+// NewVoyageService makes a new voyage service provided the external dependencies
+func NewVoyageService(
+	cfg *config.Config,
+	fr db.VesselRepo,
+	rr db.RegistryRepo,
+	wc externalrpc.WeatherClient,
+	er *emissions.Registry,
+	log logger.Logger,
+) VoyageService {
+	return &voyageService{
+		vesselService: &vesselService{
+			cfg:           cfg,
+			fuelRepo:      fr,
+			registryRepo:  rr,
+			weatherClient: wc,
+			emissions:     er,
+			logger:        log,
+		},
+	}
+}
+
+// OptimiseSpeed finds the speed per leg that minimises total fuel while arriving before the required arrival.
+// Weather depends on when every leg is sailed, so the schedule is refined until speeds stop changing.
+func (vs *voyageService) OptimiseSpeed(
+	ctx context.Context,
+	imo int,
+	drought float64,
+	departure time.Time,
+	arrival time.Time,
+	waypoints []domain.Waypoint,
+	opts domain.ConsumptionOptions,
+) (*domain.SpeedProfile, error) {
+	operation := errors.Op("service.voyageService.OptimiseSpeed")
+
+	waypoints, err := domain.NormaliseWaypoints(waypoints)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	distanceModel, err := vs.distanceModel(opts.DistanceModel)
+	if err != nil {
+		return nil, err
+	}
+	vessel, err := vs.registryRepo.GetVessel(ctx, imo)
+	if err != nil {
+		return nil, err
+	}
+	fuelType, err := vs.resolveFuelType(opts.FuelType, vessel)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	co2Factor, err := vs.emissions.CO2Factor(fuelType)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	fuelMaps, err := vs.fuelRepo.GetFuelMapWithBracketingDrToTargets(ctx, imo, []float64{drought}, departure, arrival)
+	if err != nil {
+		return nil, err
+	}
+	auxiliary := vs.auxiliaryFor(vessel)
+	versions := newFuelVersions(fuelMaps)
+	if len(versions.speeds()) == 0 {
+		return nil, errors.E(operation, errors.KindNotFound, "no fuel table for vessel")
+	}
+	// slower legs are classified manoeuvring and charged the auxiliary consumption by the pipeline,
+	// so only sailing speeds are optimised to cost candidates the same way as the evaluated schedule
+	manoeuvringSpeed := vs.stateThresholds().ManoeuvringSpeedInKnot
+	candidates := sailingSpeeds(versions.speedCandidates(), manoeuvringSpeed)
+	if len(candidates) == 0 {
+		return nil, errors.E(operation, errors.KindBadInput, "fuel table has no speeds above the manoeuvring speed")
+	}
+
+	distances := domain.LegDistances(waypoints, distanceModel)
+	if err := domain.ValidateLegDistances(distances); err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	totalDistance := 0.0
+	for _, d := range distances {
+		totalDistance += d
+	}
+	availableHours := arrival.Sub(departure).Hours()
+	if totalDistance <= 0 || availableHours <= 0 {
+		return nil, errors.E(operation, errors.KindBadInput, "voyage has no distance or time")
+	}
+	constantSpeed := totalDistance / availableHours
+	if constantSpeed > candidates[len(candidates)-1] {
+		return nil, errors.E(operation, errors.KindBadInput, "required arrival can not be met within the fuel table speeds")
+	}
+
+	// start from the constant speed schedule and refine with the weather of the optimised schedule.
+	// Weather changes with the schedule so the cheapest evaluated schedule is kept.
+	if constantSpeed >= manoeuvringSpeed {
+		candidates = withSpeed(candidates, constantSpeed)
+	}
+	speeds := constantSpeeds(len(distances), constantSpeed)
+	constantLegs, err := vs.evaluatePlan(ctx, versions, drought, auxiliary, distanceModel, waypoints, departure, distances, speeds)
+	if err != nil {
+		return nil, err
+	}
+	legs := constantLegs
+	current := constantLegs
+	factors := vs.directionalFactors()
+	for i := 0; i < maxScheduleIterations; i++ {
+		optimised := optimiseLegSpeeds(versions, factors, distances, current, candidates, availableHours)
+		if equalSpeeds(optimised, speeds) {
+			break
+		}
+		speeds = optimised
+		current, err = vs.evaluatePlan(ctx, versions, drought, auxiliary, distanceModel, waypoints, departure, distances, speeds)
+		if err != nil {
+			return nil, err
+		}
+		if calculateTotalConsumtion(current) < calculateTotalConsumtion(legs) {
+			legs = current
+		}
+	}
+
+	total := calculateTotalConsumtion(legs)
+	return &domain.SpeedProfile{
+		Departure:                           departure,
+		RequiredArrival:                     arrival,
+		Arrival:                             legs[len(legs)-1].Destination.Date,
+		FuelType:                            fuelType,
+		DistanceInNM:                        totalDistance,
+		ConsumtionInMetricTons:              total,
+		ConsumptionInCO2:                    total * co2Factor,
+		ConstantSpeedInKnot:                 constantSpeed,
+		ConstantSpeedConsumtionInMetricTons: calculateTotalConsumtion(constantLegs),
+		Legs:                                legs,
+	}, nil
+}
+
+// PlanVoyage generates the timestamps of the waypoints sailed at the given speeds and calculates the
+// consumption of the resulting route. A single speed is used for all legs, otherwise one speed per leg is required.
+func (vs *voyageService) PlanVoyage(
+	ctx context.Context,
+	imo int,
+	drought float64,
+	departure time.Time,
+	waypoints []domain.Waypoint,
+	speeds []float64,
+	opts domain.ConsumptionOptions,
+) (*domain.PlannedVoyage, error) {
+	operation := errors.Op("service.voyageService.PlanVoyage")
+
+	waypoints, err := domain.NormaliseWaypoints(waypoints)
+	if err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+
+	legs := len(waypoints) - 1
+	if len(speeds) == 1 {
+		speeds = constantSpeeds(legs, speeds[0])
+	}
+	if len(speeds) != legs {
+		return nil, errors.E(operation, errors.KindBadInput, "one speed per leg is required")
+	}
+	for _, speed := range speeds {
+		if speed <= 0 {
+			return nil, errors.E(operation, errors.KindBadInput, "speeds must be positive")
+		}
+	}
+
+	distanceModel, err := vs.distanceModel(opts.DistanceModel)
+	if err != nil {
+		return nil, err
+	}
+	distances := domain.LegDistances(waypoints, distanceModel)
+	if err := domain.ValidateLegDistances(distances); err != nil {
+		return nil, errors.E(operation, errors.KindBadInput, err)
+	}
+	route := domain.PlanRoute(waypoints, departure, distances, speeds)
+
+	routesConsumption, err := vs.GetRoutesConsumtion(ctx, imo, drought, []*domain.Route{&route}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	totalDistance := 0.0
+	for _, d := range distances {
+		totalDistance += d
+	}
+	return &domain.PlannedVoyage{
+		Departure:    departure,
+		Arrival:      route[len(route)-1].Date,
+		DistanceInNM: totalDistance,
+		Route:        routesConsumption[0],
+	}, nil
+}
+
+// evaluatePlan generates the timestamps of the waypoints for the given speeds and runs the consumption pipeline
+func (vs *voyageService) evaluatePlan(
+	ctx context.Context,
+	versions fuelVersions,
+	drought float64,
+	auxiliary domain.AuxiliaryConsumption,
+	distanceModel geodesy.DistanceModel,
+	waypoints []domain.Waypoint,
+	departure time.Time,
+	distances []float64,
+	speeds []float64,
+) ([]*domain.PointToPoint, error) {
+	route := domain.PlanRoute(waypoints, departure, distances, speeds)
+	pointToPoints := route.ConvertToP2P(distanceModel)
+	domain.AssignDraughts(pointToPoints, drought)
+	if err := vs.calculateWeather(ctx, pointToPoints); err != nil {
+		return nil, err
+	}
+	vs.calculateConsumption(ctx, versions, auxiliary, pointToPoints)
+	return pointToPoints, nil
+}
+
+// optimiseLegSpeeds minimises total fuel subject to the time available using a Lagrange multiplier on time.
+// For a time cost lambda every leg picks the speed minimising fuel + lambda * hours, lambda is bisected
+// to the smallest value whose schedule fits in the available hours.
+// Every leg keeps the draught, weather and wind sector of the last evaluated schedule.
+func optimiseLegSpeeds(
+	versions fuelVersions,
+	factors domain.DirectionalFactors,
+	distances []float64,
+	legs []*domain.PointToPoint,
+	candidates []float64,
+	availableHours float64,
+) []float64 {
+	speeds, hours := legSpeedsForLambda(versions, factors, distances, legs, candidates, 0)
+	if hours <= availableHours {
+		// fuel optimal speeds already arrive on time
+		return speeds
+	}
+
+	low, high := 0.0, 1.0
+	for {
+		_, hours = legSpeedsForLambda(versions, factors, distances, legs, candidates, high)
+		if hours <= availableHours || high > 1e12 {
+			break
+		}
+		high *= 2
+	}
+	for i := 0; i < lambdaSearchIterations; i++ {
+		mid := (low + high) / 2
+		if _, hours = legSpeedsForLambda(versions, factors, distances, legs, candidates, mid); hours <= availableHours {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	speeds, _ = legSpeedsForLambda(versions, factors, distances, legs, candidates, high)
+	return speeds
+}
+
+// legSpeedsForLambda returns the speed of every leg minimising fuel + lambda * hours and the total hours
+func legSpeedsForLambda(
+	versions fuelVersions,
+	factors domain.DirectionalFactors,
+	distances []float64,
+	legs []*domain.PointToPoint,
+	candidates []float64,
+	lambda float64,
+) ([]float64, float64) {
+	speeds := make([]float64, len(distances))
+	totalHours := 0.0
+	for i, distance := range distances {
+		bestCost := math.Inf(1)
+		for _, speed := range candidates {
+			hours := distance / speed
+			daily, _ := versions.forLeg(legs[i]).windConsumption(legs[i].Draught, speed, legs[i].AvgWeatherInBeaufort, legs[i].WindSector, factors)
+			cost := daily*hours/24 + lambda*hours
+			if cost < bestCost {
+				bestCost, speeds[i] = cost, speed
+			}
+		}
+		totalHours += distance / speeds[i]
+	}
+	return speeds, totalHours
+}
+
+// speedCandidates returns the speeds between the lowest and highest speed of the fuel table versions in speedStep steps
+func (fvs fuelVersions) speedCandidates() []float64 {
+	speeds := fvs.speeds()
+	if len(speeds) == 0 {
+		return nil
+	}
+	minSpeed, maxSpeed := speeds[0], speeds[len(speeds)-1]
+	if minSpeed <= 0 {
+		minSpeed = speedStep
+	}
+	candidates := []float64{}
+	for i := 0; minSpeed+float64(i)*speedStep < maxSpeed; i++ {
+		candidates = append(candidates, minSpeed+float64(i)*speedStep)
+	}
+	return append(candidates, maxSpeed)
+}
+
+// sailingSpeeds returns the sorted candidates at or above the manoeuvring speed
+func sailingSpeeds(candidates []float64, manoeuvringSpeed float64) []float64 {
+	return candidates[sort.SearchFloat64s(candidates, manoeuvringSpeed):]
+}
+
+// withSpeed adds speed to the sorted candidates if it is not one of them
+func withSpeed(candidates []float64, speed float64) []float64 {
+	i := sort.SearchFloat64s(candidates, speed)
+	if i < len(candidates) && candidates[i] == speed {
+		return candidates
+	}
+	candidates = append(candidates, 0)
+	copy(candidates[i+1:], candidates[i:])
+	candidates[i] = speed
+	return candidates
+}
+
+func constantSpeeds(legs int, speed float64) []float64 {
+	speeds := make([]float64, legs)
+	for i := range speeds {
+		speeds[i] = speed
+	}
+	return speeds
+}
+
+func equalSpeeds(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS vessels CASCADE;
\ No newline at end of file
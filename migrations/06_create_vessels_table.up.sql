@@ -0,0 +1,5 @@
+CREATE TABLE vessels (
+  imo int PRIMARY KEY,
+  ship_type varchar(64) NOT NULL,
+  dwt float8 NOT NULL
+);
@@ -0,0 +1,4 @@
+ALTER TABLE vessels
+  DROP COLUMN IF EXISTS manoeuvring_consumption,
+  DROP COLUMN IF EXISTS anchored_consumption,
+  DROP COLUMN IF EXISTS berthed_consumption;
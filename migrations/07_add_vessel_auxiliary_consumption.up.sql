@@ -0,0 +1,4 @@
+ALTER TABLE vessels
+  ADD COLUMN manoeuvring_consumption float8,
+  ADD COLUMN anchored_consumption float8,
+  ADD COLUMN berthed_consumption float8;
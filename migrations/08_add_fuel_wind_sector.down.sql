@@ -0,0 +1 @@
+ALTER TABLE fuel DROP COLUMN IF EXISTS wind_sector;
@@ -0,0 +1,2 @@
+ALTER TABLE fuel
+  ADD COLUMN wind_sector varchar(16) CHECK (wind_sector IN ('head', 'beam', 'following'));
@@ -0,0 +1,4 @@
+-- only the latest version of every vessel is kept
+DELETE FROM fuel f USING fuel_versions v WHERE f.version_id = v.id AND v.valid_to IS NOT NULL;
+ALTER TABLE fuel DROP COLUMN IF EXISTS version_id;
+DROP TABLE IF EXISTS fuel_versions;
@@ -0,0 +1,22 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE fuel_versions (
+  id  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  imo int NOT NULL,
+  valid_from timestamptz,
+  valid_to timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CHECK (valid_from IS NULL OR valid_to IS NULL OR valid_to > valid_from),
+  EXCLUDE USING gist (imo WITH =, tstzrange(valid_from, valid_to) WITH &&)
+);
+
+CREATE INDEX idx_fuel_versions_imo ON fuel_versions(imo);
+
+ALTER TABLE fuel ADD COLUMN version_id UUID REFERENCES fuel_versions(id) ON DELETE CASCADE;
+
+-- rows imported so far are the only version of their vessel, in effect since ever
+INSERT INTO fuel_versions (imo) SELECT DISTINCT imo FROM fuel;
+UPDATE fuel f SET version_id = v.id FROM fuel_versions v WHERE v.imo = f.imo;
+
+ALTER TABLE fuel ALTER COLUMN version_id SET NOT NULL;
+CREATE INDEX idx_fuel_version ON fuel(version_id);
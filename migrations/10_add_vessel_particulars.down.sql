@@ -0,0 +1,5 @@
+ALTER TABLE vessels
+  DROP COLUMN IF EXISTS name,
+  DROP COLUMN IF EXISTS gt,
+  DROP COLUMN IF EXISTS main_fuel,
+  DROP COLUMN IF EXISTS design_speed;
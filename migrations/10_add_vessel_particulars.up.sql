@@ -0,0 +1,5 @@
+ALTER TABLE vessels
+  ADD COLUMN name varchar(128) NOT NULL DEFAULT '',
+  ADD COLUMN gt float8 CHECK (gt > 0),
+  ADD COLUMN main_fuel varchar(16),
+  ADD COLUMN design_speed float8 CHECK (design_speed > 0);
@@ -0,0 +1,24 @@
+-- only the sample vessels registered by the up migration are removed
+DELETE FROM vessels
+WHERE (imo, name) IN (
+  (2345674, 'Sample Model 1'),
+  (1234567, 'Sample Model 2'),
+  (3456781, 'Sample Model 3'),
+  (4567898, 'Sample Model 4')
+);
+
+UPDATE fuel SET imo = CASE imo
+  WHEN 2345674 THEN 234567
+  WHEN 1234567 THEN 123456
+  WHEN 3456781 THEN 345678
+  WHEN 4567898 THEN 456789
+END
+WHERE imo IN (2345674, 1234567, 3456781, 4567898);
+
+UPDATE fuel_versions SET imo = CASE imo
+  WHEN 2345674 THEN 234567
+  WHEN 1234567 THEN 123456
+  WHEN 3456781 THEN 345678
+  WHEN 4567898 THEN 456789
+END
+WHERE imo IN (2345674, 1234567, 3456781, 4567898);
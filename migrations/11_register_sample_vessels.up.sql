@@ -0,0 +1,24 @@
+-- the sample fuel tables were imported with made up imos, they get a valid check digit appended
+-- and are registered so calculations of the sample vessels find their particulars
+UPDATE fuel_versions SET imo = CASE imo
+  WHEN 234567 THEN 2345674
+  WHEN 123456 THEN 1234567
+  WHEN 345678 THEN 3456781
+  WHEN 456789 THEN 4567898
+END
+WHERE imo IN (234567, 123456, 345678, 456789);
+
+UPDATE fuel SET imo = CASE imo
+  WHEN 234567 THEN 2345674
+  WHEN 123456 THEN 1234567
+  WHEN 345678 THEN 3456781
+  WHEN 456789 THEN 4567898
+END
+WHERE imo IN (234567, 123456, 345678, 456789);
+
+INSERT INTO vessels (imo, name, ship_type, dwt) VALUES
+  (2345674, 'Sample Model 1', 'bulk_carrier', 81000),
+  (1234567, 'Sample Model 2', 'bulk_carrier', 81000),
+  (3456781, 'Sample Model 3', 'bulk_carrier', 81000),
+  (4567898, 'Sample Model 4', 'bulk_carrier', 81000)
+ON CONFLICT (imo) DO NOTHING;